@@ -16,6 +16,7 @@ type Binder struct {
 	stringRecievers  []*receiver[string]
 	intReceivers     []*receiver[int]
 	float64Receivers []*receiver[float64]
+	secretReceivers  []*receiver[Secret]
 }
 
 // Binds a string configuration value. Will be set after the binder function is executed
@@ -24,6 +25,7 @@ func (binder *Binder) StringVar(dest *string, key string) {
 		pointer: dest,
 		key:     key,
 	})
+	binder.cfg.usage.add(Field{Key: key, Type: TypeString})
 }
 
 // Binds an integer configuration value. Will be set after the binder function is executed
@@ -32,6 +34,7 @@ func (binder *Binder) IntVar(dest *int, key string) {
 		pointer: dest,
 		key:     key,
 	})
+	binder.cfg.usage.add(Field{Key: key, Type: TypeInt})
 }
 
 // Binds a float64 configuration value. Will be set after the binder function is executed
@@ -40,6 +43,16 @@ func (binder *Binder) Float64Var(dest *float64, key string) {
 		pointer: dest,
 		key:     key,
 	})
+	binder.cfg.usage.add(Field{Key: key, Type: TypeFloat64})
+}
+
+// Binds a secret configuration value. Will be set after the binder function is executed
+func (binder *Binder) SecretVar(dest *Secret, key string) {
+	binder.secretReceivers = append(binder.secretReceivers, &receiver[Secret]{
+		pointer: dest,
+		key:     key,
+	})
+	binder.cfg.usage.add(Field{Key: key, Type: TypeSecret})
 }
 
 func newBinder(cfg *Config) *Binder {
@@ -48,13 +61,15 @@ func newBinder(cfg *Config) *Binder {
 		stringRecievers:  make([]*receiver[string], 0),
 		intReceivers:     make([]*receiver[int], 0),
 		float64Receivers: make([]*receiver[float64], 0),
+		secretReceivers:  make([]*receiver[Secret], 0),
 	}
 }
 
 func (binder *Binder) execute() error {
-	// Get config values
+	// Get config values from a single load, even if configuration is reloaded while binding
+	s := binder.cfg.snapshot()
 	for _, r := range binder.stringRecievers {
-		v, err := binder.cfg.GetString(r.key)
+		v, err := s.getString(r.key)
 		if err != nil {
 			return err
 		}
@@ -62,7 +77,7 @@ func (binder *Binder) execute() error {
 	}
 
 	for _, r := range binder.intReceivers {
-		v, err := binder.cfg.GetInt(r.key)
+		v, err := s.getInt(r.key)
 		if err != nil {
 			return err
 		}
@@ -70,7 +85,15 @@ func (binder *Binder) execute() error {
 	}
 
 	for _, r := range binder.float64Receivers {
-		v, err := binder.cfg.GetFloat64(r.key)
+		v, err := s.getFloat64(r.key)
+		if err != nil {
+			return err
+		}
+		r.value = v
+	}
+
+	for _, r := range binder.secretReceivers {
+		v, err := s.getSecret(r.key)
 		if err != nil {
 			return err
 		}
@@ -90,5 +113,9 @@ func (binder *Binder) execute() error {
 		r.execute()
 	}
 
+	for _, r := range binder.secretReceivers {
+		r.execute()
+	}
+
 	return nil
 }
@@ -1,56 +1,251 @@
 package cfg
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"strconv"
-	"strings"
+	"sync"
 
 	"github.com/jaredhughes1012/cfg/internal/mapconvert"
 )
 
 // Manages loading and access of external configuration data
 type Config struct {
-	data    map[string]any
-	loaders []Loader
+	data        map[string]any
+	loaders     []*loaderEntry
+	fields      []Field
+	usage       *usageRegistry
+	normalizer  KeyNormalizer
+	delimiter   string
+	mergeRules  map[string]MergeStrategy
+	report      LoadReport
+	concurrency int
+	provenance  map[string]Provenance
+	resolvers   map[string]Resolver
+	trusted     map[string]bool
+
+	// Guards loaded state so values can be read while configuration is reloaded
+	state *sync.RWMutex
+
+	// Ensures only one load runs at a time
+	loading *sync.Mutex
 }
 
 // Creates a new configuration instance
 func New() *Config {
 	return &Config{
-		data:    nil,
-		loaders: make([]Loader, 0),
+		data:        nil,
+		loaders:     make([]*loaderEntry, 0),
+		fields:      make([]Field, 0),
+		usage:       newUsageRegistry(),
+		normalizer:  NormalizeStandard,
+		delimiter:   ":",
+		mergeRules:  make(map[string]MergeStrategy),
+		concurrency: 1,
+		resolvers:   make(map[string]Resolver),
+		state:       &sync.RWMutex{},
+		loading:     &sync.Mutex{},
 	}
 }
 
-// Adds a new config loader to this configuration instance
+// Adds a new config loader to this configuration instance with normal priority. Loaders added later
+// override values from loaders added earlier with the same priority
 func (cfg *Config) Add(l Loader) {
-	cfg.loaders = append(cfg.loaders, l)
+	cfg.AddNamed("", l, PriorityNormal)
 }
 
-// Loads from all registered loaders. If any loaders fail, will return the error
+// Loads from all registered loaders in order of priority. If any loaders fail, will return the error
+// unless the loader's policy allows the failure. The outcome of every loader, including failures
+// allowed by policy, is available from LoadWithReport or from Report once Load returns.
+// Keys from each loader are normalized before merging, and an error is returned if two keys from the
+// same loader normalize to the same key, unless the loader implements DuplicateKeysLoader. A nil
+// value (null in JSON) deletes the key and everything nested under it that was provided by earlier
+// loaders. References to external sources in the merged configuration are then resolved, see
+// RegisterResolver. If fields have been declared, the merged configuration is validated against
+// them and a *ValidationError describing every violation is returned if it does not match
 func (cfg *Config) Load() error {
+	return cfg.LoadContext(context.Background())
+}
+
+// Loads from all registered loaders like Load. The context is passed to every loader that implements
+// ContextLoader, and Load fails with the context's error if it is done before all loaders finish
+func (cfg *Config) LoadContext(ctx context.Context) error {
+	_, err := cfg.LoadContextWithReport(ctx)
+	return err
+}
+
+// Loads from all registered loaders like Load, returning the outcome of every loader along with any
+// error. Unlike Report, the report always belongs to this load even if another load runs after it
+func (cfg *Config) LoadWithReport() (LoadReport, error) {
+	return cfg.LoadContextWithReport(context.Background())
+}
+
+// Loads from all registered loaders like LoadContext, returning the outcome of every loader along
+// with any error
+func (cfg *Config) LoadContextWithReport(ctx context.Context) (LoadReport, error) {
+	cfg.loading.Lock()
+	defer cfg.loading.Unlock()
+
 	data := make(map[string]any)
+	provenance := make(map[string]Provenance)
+	report := LoadReport{Results: make([]LoaderResult, 0, len(cfg.loaders))}
+	defer func() {
+		cfg.state.Lock()
+		defer cfg.state.Unlock()
+		cfg.report = report
+	}()
+	errs := make([]error, 0)
+	parallel := cfg.concurrency != 1
+	wait := cfg.startLoaders(ctx)
+	rules := cfg.normalizedMergeRules()
+
+	for i, entry := range cfg.loaders {
+		res := wait(i)
+		d, err := res.data, res.err
+		result := LoaderResult{Name: entry.name, Status: LoadSucceeded}
+		stale := false
 
-	for _, loader := range cfg.loaders {
-		d, err := loader.Load()
 		if err != nil {
-			return err
+			cached, ok := entry.loadLastGood()
+			if !ok || ctx.Err() != nil {
+				status, fatal := applyPolicy(ctx, entry.loader, err)
+				report.Results = append(report.Results, LoaderResult{Name: entry.name, Status: status, Err: err})
+				if fatal && !parallel {
+					return report, err
+				} else if fatal {
+					errs = append(errs, err)
+				}
+				continue
+			}
+
+			d, stale = cached, true
+			result.Status, result.Err = LoadStale, err
+		} else {
+			result.Err = entry.storeLastGood(d)
 		}
+		report.Results = append(report.Results, result)
 
-		data = mapconvert.Fold(d, data)
+		if !hasLiteralKeys(entry.loader) {
+			d = mapconvert.ExpandKeys(d, cfg.delim())
+		}
+		d, err = mapconvert.NormalizeKeysWith(d, cfg.normalizeSegment, mapconvert.NormalizeOptions{
+			AllowDuplicates: allowsDuplicateKeys(entry.loader),
+		})
+		if err != nil && !parallel {
+			return report, err
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		p := Provenance{
+			Loader:    entry.name,
+			Stale:     stale,
+			Sensitive: isSensitive(entry.loader),
+			Version:   loaderVersion(entry.loader),
+		}
+		for k := range mapconvert.Flatten(d, cfg.delim()) {
+			provenance[k] = p
+		}
+
+		data = mapconvert.FoldWith(d, data, mapconvert.FoldOptions{
+			Delim: cfg.delim(),
+			Rules: rules,
+		})
+	}
+
+	if len(errs) == 1 {
+		return report, errs[0]
+	} else if len(errs) > 1 {
+		return report, errors.Join(errs...)
+	}
+
+	resolved, err := cfg.resolve(ctx, data, provenance)
+	if err != nil {
+		return report, err
 	}
 
-	data = mapconvert.Flatten(data, ":")
-	data = mapconvert.ConvertKeys(data, func(key string) string {
-		return strings.Replace(strings.ToLower(key), "_", "", -1)
-	})
+	data = mapconvert.Flatten(data, cfg.delim())
 
+	if err := cfg.validate(data); err != nil {
+		return report, err
+	}
+
+	cfg.markResolved(data, provenance, resolved)
+
+	// Keys deleted by later loaders have no provenance
+	for k := range provenance {
+		if data[k] == nil {
+			delete(provenance, k)
+		}
+	}
+
+	cfg.state.Lock()
+	defer cfg.state.Unlock()
 	cfg.data = data
-	return nil
+	cfg.provenance = provenance
+	return report, nil
+}
+
+func toString(key string, v any) (string, error) {
+	if vStr, ok := v.(string); ok {
+		return vStr, nil
+	}
+
+	return "", fmt.Errorf("key %s does not have a valid string value", key)
+}
+
+func toInt(key string, v any) (int, error) {
+	if vInt, ok := v.(int); ok {
+		return vInt, nil
+	} else if vFloat64, ok := v.(float64); ok && vFloat64 == math.Trunc(vFloat64) {
+		// Numbers decoded from JSON are always float64
+		return int(vFloat64), nil
+	} else if vStr, ok := v.(string); ok {
+		return strconv.Atoi(vStr)
+	} else {
+		return 0, fmt.Errorf("key %s does not have a valid integer value", key)
+	}
+}
+
+func toFloat64(key string, v any) (float64, error) {
+	if vInt, ok := v.(int); ok {
+		return float64(vInt), nil
+	} else if vFloat32, ok := v.(float32); ok {
+		return float64(vFloat32), nil
+	} else if vFloat64, ok := v.(float64); ok {
+		return vFloat64, nil
+	} else if vStr, ok := v.(string); ok {
+		return strconv.ParseFloat(vStr, 64)
+	} else {
+		return 0, fmt.Errorf("key %s does not have a valid float64 value", key)
+	}
+}
+
+// Values from a single load, so that several values can be read without mixing values from two loads
+type snapshot struct {
+	cfg  *Config
+	data map[string]any
+}
+
+// Gets the values from the most recent load. Loads replace the data rather than modifying it, so the
+// snapshot does not change when configuration is loaded again
+func (cfg *Config) snapshot() snapshot {
+	cfg.state.RLock()
+	defer cfg.state.RUnlock()
+	return snapshot{cfg: cfg, data: cfg.data}
 }
 
-func (cfg Config) getVal(key string) (any, error) {
-	v := cfg.data[key]
+// Gets the value at the given key, normalizing it first. Returns nil if the key is not found
+func (s snapshot) lookup(key string) any {
+	return s.data[s.cfg.normalizeKey(key)]
+}
+
+func (s snapshot) getVal(key string) (any, error) {
+	v := s.lookup(key)
 	if v == nil {
 		return nil, fmt.Errorf("%s not found", key)
 	}
@@ -58,19 +253,49 @@ func (cfg Config) getVal(key string) (any, error) {
 	return v, nil
 }
 
-// Gets a string config value, returns an error if the value is not found
-func (cfg Config) GetString(key string) (string, error) {
-	if v, err := cfg.getVal(key); err != nil {
+func (s snapshot) getString(key string) (string, error) {
+	v, err := s.getVal(key)
+	if err != nil {
 		return "", err
-	} else if vStr, ok := v.(string); ok {
-		return vStr, nil
-	} else {
-		return "", fmt.Errorf("key %s does not have a valid string value", key)
 	}
+
+	return toString(key, v)
+}
+
+func (s snapshot) getInt(key string) (int, error) {
+	v, err := s.getVal(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return toInt(key, v)
+}
+
+func (s snapshot) getFloat64(key string) (float64, error) {
+	v, err := s.getVal(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return toFloat64(key, v)
+}
+
+func (s snapshot) getSecret(key string) (Secret, error) {
+	v, err := s.getString(key)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	return NewSecret(v), nil
+}
+
+// Gets a string config value, returns an error if the value is not found
+func (cfg *Config) GetString(key string) (string, error) {
+	return cfg.snapshot().getString(key)
 }
 
 // Gets a string config value, panics if value is not found
-func (cfg Config) MustGetString(key string) string {
+func (cfg *Config) MustGetString(key string) string {
 	data, err := cfg.GetString(key)
 	if err != nil {
 		panic(err)
@@ -80,20 +305,12 @@ func (cfg Config) MustGetString(key string) string {
 }
 
 // Gets an integer config value, returns an error if the value is not found
-func (cfg Config) GetInt(key string) (int, error) {
-	if v, err := cfg.getVal(key); err != nil {
-		return 0, err
-	} else if vInt, ok := v.(int); ok {
-		return vInt, nil
-	} else if vStr, ok := v.(string); ok {
-		return strconv.Atoi(vStr)
-	} else {
-		return 0, fmt.Errorf("key %s does not have a valid integer value", key)
-	}
+func (cfg *Config) GetInt(key string) (int, error) {
+	return cfg.snapshot().getInt(key)
 }
 
 // Gets a integer config value, panics if value is not found
-func (cfg Config) MustGetInt(key string) int {
+func (cfg *Config) MustGetInt(key string) int {
 	data, err := cfg.GetInt(key)
 	if err != nil {
 		panic(err)
@@ -103,24 +320,12 @@ func (cfg Config) MustGetInt(key string) int {
 }
 
 // Gets an integer config value, returns an error if the value is not found
-func (cfg Config) GetFloat64(key string) (float64, error) {
-	if v, err := cfg.getVal(key); err != nil {
-		return 0, err
-	} else if vInt, ok := v.(int); ok {
-		return float64(vInt), nil
-	} else if vFloat32, ok := v.(float32); ok {
-		return float64(vFloat32), nil
-	} else if vFloat64, ok := v.(float64); ok {
-		return vFloat64, nil
-	} else if vStr, ok := v.(string); ok {
-		return strconv.ParseFloat(vStr, 64)
-	} else {
-		return 0, fmt.Errorf("key %s does not have a valid float64 value", key)
-	}
+func (cfg *Config) GetFloat64(key string) (float64, error) {
+	return cfg.snapshot().getFloat64(key)
 }
 
 // Gets a integer config value, panics if value is not found
-func (cfg Config) MustGetFloat64(key string) float64 {
+func (cfg *Config) MustGetFloat64(key string) float64 {
 	data, err := cfg.GetFloat64(key)
 	if err != nil {
 		panic(err)
@@ -129,19 +334,43 @@ func (cfg Config) MustGetFloat64(key string) float64 {
 	return data
 }
 
+// Gets a secret config value, returns an error if the value is not found. Secrets are stored as
+// strings in the underlying sources but are redacted when printed or logged
+func (cfg *Config) GetSecret(key string) (Secret, error) {
+	return cfg.snapshot().getSecret(key)
+}
+
+// Gets a secret config value, panics if value is not found
+func (cfg *Config) MustGetSecret(key string) Secret {
+	data, err := cfg.GetSecret(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return data
+}
+
 // Binds multiple configuration values simultaneously. The binder registers pointers for configuration
-// values, which are all resolved and set simultaneously. If any bound values are not found, the error
-// will be returned and none of the pointers will be modified
-func (cfg Config) Bind(bindFunc func(*Binder)) error {
-	binder := newBinder(&cfg)
+// values, which are all resolved from the same load and set simultaneously. If any bound values are
+// not found, the error will be returned and none of the pointers will be modified
+func (cfg *Config) Bind(bindFunc func(*Binder)) error {
+	binder := newBinder(cfg)
 	bindFunc(binder)
 	return binder.execute()
 }
 
 // Generic structure used to load configuration from a source into an object. cfg will process and
 // flatten this map internally. Loaders should not modify any names of config values, this should
-// be manged internally by cfg
+// be manged internally by cfg. Keys containing the delimiter are split into nested keys, so
+// {"db:port": 5432} is the same as {"db": {"port": 5432}}; escape the delimiter with a backslash to
+// keep it in the key e.g. "http\://host". Loaders that read documents whose keys should be kept as
+// they are implement LiteralKeysLoader instead. Loaders that read from slow or remote sources should
+// also implement ContextLoader so they can be cancelled
 type Loader interface {
 	// Loads configuration from a source into a map
 	Load() (map[string]any, error)
 }
+
+// Decodes a document, such as a JSON file or HTTP response body, into a map. Loaders that read
+// documents accept decoders so that they can support any format
+type Decoder func(r io.Reader) (map[string]any, error)
@@ -2,6 +2,9 @@ package cfg
 
 import (
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -117,6 +120,20 @@ func Test_Config_GetString(t *testing.T) {
 			expected: "val",
 			isErr:    false,
 		},
+		{
+			name:     "Escaped delimiter",
+			loader:   newTestLoader(map[string]any{"hosts": map[string]any{`http\://host`: "val"}}, nil),
+			key:      `hosts:http\://host`,
+			expected: "val",
+			isErr:    false,
+		},
+		{
+			name:     "Escaped delimiter not split",
+			loader:   newTestLoader(map[string]any{"hosts": map[string]any{`http\://host`: "val"}}, nil),
+			key:      "hosts:http",
+			expected: "",
+			isErr:    true,
+		},
 		{
 			name:     "With underscore",
 			loader:   newTestLoader(map[string]any{"key_test": "val"}, nil),
@@ -384,3 +401,135 @@ func Test_Config_Bind(t *testing.T) {
 		t.Errorf("Float64 %f != %f", float64Expected, float64Actual)
 	}
 }
+
+func Test_Config_Load_Tombstone(t *testing.T) {
+	cfg, err := newConfigAndLoad(
+		newTestLoader(map[string]any{
+			"metrics": map[string]any{"host": "localhost", "port": 9090},
+			"name":    "svc",
+			"level":   "info",
+		}, nil),
+		newTestLoader(map[string]any{
+			"metrics": nil,
+			"level":   nil,
+		}, nil),
+	)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for _, key := range []string{"metrics:host", "metrics:port", "level"} {
+		if _, err := cfg.GetString(key); err == nil {
+			t.Errorf("Key %s found after deletion", key)
+		}
+	}
+	if actual := cfg.MustGetString("name"); actual != "svc" {
+		t.Errorf("Name svc != %s", actual)
+	}
+}
+
+// Run with -race to detect reads of loaded state that are not guarded against reloads
+func Test_Config_Load_Concurrent(t *testing.T) {
+	cfg, err := newConfigAndLoad(newTestLoader(testData, nil))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if actual := cfg.MustGetString("key"); actual != "value" {
+				t.Errorf("value != %s", actual)
+			}
+			if _, ok := cfg.Provenance("key"); !ok {
+				t.Error("No provenance for key")
+			}
+			_ = cfg.Report()
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		if err := cfg.Load(); err != nil {
+			t.Errorf("%v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+// Provides the number of times it has been loaded under many keys
+type countingLoader struct {
+	loads atomic.Int64
+}
+
+func (loader *countingLoader) Load() (map[string]any, error) {
+	n := int(loader.loads.Add(1))
+	data := make(map[string]any)
+	for i := 0; i < 20; i++ {
+		data[fmt.Sprintf("key%d", i)] = n
+	}
+
+	return data, nil
+}
+
+// Checks that binding never mixes values from two loads while configuration is reloaded
+func Test_Config_Bind_Concurrent(t *testing.T) {
+	cfg := New()
+	cfg.Add(&countingLoader{})
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	defer wg.Wait()
+	defer close(done)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if err := cfg.Load(); err != nil {
+				t.Errorf("%v", err)
+			}
+		}
+	}()
+
+	for i := 0; i < 1000; i++ {
+		values := make([]int, 20)
+		err := cfg.Bind(func(b *Binder) {
+			for j := range values {
+				b.IntVar(&values[j], fmt.Sprintf("key%d", j))
+			}
+		})
+		if err != nil {
+			t.Fatalf("%v", err)
+		} else if values[0] != values[len(values)-1] {
+			t.Fatalf("Bind mixed loads %d != %d", values[0], values[len(values)-1])
+		}
+
+		var settings struct {
+			First int `cfg:"key0"`
+			Last  int `cfg:"key19"`
+		}
+		if err := cfg.BindStruct(&settings); err != nil {
+			t.Fatalf("%v", err)
+		} else if settings.First != settings.Last {
+			t.Fatalf("BindStruct mixed loads %d != %d", settings.First, settings.Last)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package cfgaws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Special options used to control how AWS services are called
+type Options struct {
+	// AWS region of the service. Uses the AWS_REGION environment variable if not set
+	Region string
+
+	// If set, requests are sent to this URL instead of the regional endpoint of the service, such as
+	// a VPC endpoint or a local emulator
+	Endpoint string
+
+	// Provides credentials used to sign requests. Uses EnvCredentials if not set
+	Credentials CredentialsProvider
+
+	// If set, used for requests instead of a client created with Timeout
+	Client *http.Client
+
+	// Timeout for each request
+	Timeout time.Duration
+}
+
+// Standard options that are used if none is provided
+var StandardOptions = Options{
+	Timeout: 30 * time.Second,
+}
+
+// Client for AWS services that use the JSON protocol, such as SSM and Secrets Manager
+type client struct {
+	service string
+
+	// Prefix of the X-Amz-Target header e.g. "AmazonSSM"
+	target string
+
+	opts *Options
+	http *http.Client
+}
+
+// Error returned by an AWS JSON API
+type apiError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// Gets the name of the error without its namespace e.g. "ResourceNotFoundException"
+func (e apiError) code() string {
+	return e.Type[strings.LastIndex(e.Type, "#")+1:]
+}
+
+func newClient(service, target string, opts *Options) *client {
+	if opts == nil {
+		opts = &StandardOptions
+	}
+
+	o := *opts
+	if o.Region == "" {
+		o.Region = os.Getenv("AWS_REGION")
+	}
+	if o.Credentials == nil {
+		o.Credentials = EnvCredentials
+	}
+
+	httpClient := o.Client
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: o.Timeout}
+	}
+
+	return &client{service: service, target: target, opts: &o, http: httpClient}
+}
+
+func (c *client) endpoint() string {
+	if c.opts.Endpoint != "" {
+		return c.opts.Endpoint
+	}
+
+	return fmt.Sprintf("https://%s.%s.amazonaws.com/", c.service, c.opts.Region)
+}
+
+// Calls an operation of the service, decoding the response into out. API errors are returned as
+// their error code and message
+func (c *client) call(ctx context.Context, operation string, in, out any) (*apiError, error) {
+	if c.opts.Region == "" {
+		return nil, fmt.Errorf("%s: region is not set", c.service)
+	}
+
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", fmt.Sprintf("%s.%s", c.target, operation))
+
+	creds, err := c.opts.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.service, err)
+	}
+	if err := signV4(req, creds, c.opts.Region, c.service, time.Now()); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr apiError
+		if err := json.Unmarshal(respBody, &apiErr); err != nil || apiErr.Type == "" {
+			return nil, fmt.Errorf("%s %s: unexpected status %s", c.service, operation, resp.Status)
+		}
+		return &apiErr, nil
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", c.service, operation, err)
+	}
+
+	return nil, nil
+}
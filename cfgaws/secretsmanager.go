@@ -0,0 +1,95 @@
+package cfgaws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/internal/mapconvert"
+)
+
+// Config loader designed to load named secrets from Secrets Manager. Secrets storing a JSON object
+// are nested under their key, and other secrets are loaded as a single string value. All values
+// loaded are sensitive
+type SecretsManagerLoader struct {
+	secrets map[string]string
+	client  *client
+}
+
+// Loads configuration from a source into a map
+func (loader *SecretsManagerLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// Loads configuration from a source into a map, cancelling requests if the context is done
+func (loader *SecretsManagerLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	keys := make([]string, 0, len(loader.secrets))
+	for k := range loader.secrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	data := make(map[string]any)
+	for _, key := range keys {
+		id := loader.secrets[key]
+
+		var out struct {
+			SecretString *string
+			SecretBinary []byte
+		}
+		apiErr, err := loader.client.call(ctx, "GetSecretValue", map[string]string{"SecretId": id}, &out)
+		if err != nil {
+			return nil, err
+		} else if apiErr != nil && apiErr.code() == "ResourceNotFoundException" {
+			return nil, fmt.Errorf("secret %s: %w", id, cfg.ErrNotFound)
+		} else if apiErr != nil {
+			return nil, fmt.Errorf("secret %s: %s: %s", id, apiErr.code(), apiErr.Message)
+		}
+
+		raw := out.SecretBinary
+		if out.SecretString != nil {
+			raw = []byte(*out.SecretString)
+		}
+
+		var v any = string(raw)
+		var obj map[string]any
+		if json.NewDecoder(bytes.NewReader(raw)).Decode(&obj) == nil {
+			v = obj
+		}
+
+		entry := make(map[string]any)
+		mapconvert.SetPath(entry, strings.Split(strings.Trim(key, "/"), "/"), v)
+		data = mapconvert.Fold(entry, data)
+	}
+
+	return data, nil
+}
+
+// Reports that all values loaded from Secrets Manager are sensitive
+func (loader *SecretsManagerLoader) Sensitive() bool {
+	return true
+}
+
+// Reports that keys of secrets are used as they are, as they may contain the delimiter
+func (loader *SecretsManagerLoader) LiteralKeys() bool {
+	return true
+}
+
+var _ cfg.Loader = (*SecretsManagerLoader)(nil)
+var _ cfg.ContextLoader = (*SecretsManagerLoader)(nil)
+var _ cfg.SensitiveLoader = (*SecretsManagerLoader)(nil)
+var _ cfg.LiteralKeysLoader = (*SecretsManagerLoader)(nil)
+
+// Creates a new cfg loader designed to load secrets from Secrets Manager. Secrets are given by the
+// key they are loaded under, split on "/", and their name or ARN e.g. {"db": "prod/app/db"} loads
+// the "password" field of the secret as "db:password". Uses the standard options if none is provided
+func NewSecretsManagerLoader(secrets map[string]string, opts *Options) *SecretsManagerLoader {
+	return &SecretsManagerLoader{
+		secrets: secrets,
+		client:  newClient("secretsmanager", "secretsmanager", opts),
+	}
+}
@@ -0,0 +1,80 @@
+package cfgaws
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jaredhughes1012/cfg"
+)
+
+func Test_SecretsManagerLoader_Load(t *testing.T) {
+	secrets := map[string]map[string]any{
+		"prod/app/db":  {"SecretString": `{"username": "app", "password": "hunter2"}`},
+		"prod/app/key": {"SecretString": "plain"},
+		"prod/app/bin": {"SecretBinary": []byte("binary")},
+	}
+
+	server := newFakeAws(t, "secretsmanager", map[string]func(map[string]any) (int, any){
+		"GetSecretValue": func(in map[string]any) (int, any) {
+			out, ok := secrets[in["SecretId"].(string)]
+			if !ok {
+				return http.StatusBadRequest, map[string]any{
+					"__type":  "com.amazonaws.secretsmanager#ResourceNotFoundException",
+					"message": "not found",
+				}
+			}
+			return http.StatusOK, out
+		},
+	})
+	defer server.Close()
+
+	cases := []struct {
+		name     string
+		secrets  map[string]string
+		expected map[string]string
+		notFound bool
+	}{
+		{
+			name:     "JSON secret",
+			secrets:  map[string]string{"db": "prod/app/db"},
+			expected: map[string]string{"db:username": "app", "db:password": "hunter2"},
+		},
+		{
+			name:     "String and binary secrets",
+			secrets:  map[string]string{"api/key": "prod/app/key", "bin": "prod/app/bin"},
+			expected: map[string]string{"api:key": "plain", "bin": "binary"},
+		},
+		{
+			name:     "Secret not found",
+			secrets:  map[string]string{"db": "prod/app/missing"},
+			notFound: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := cfg.New()
+			config.Add(NewSecretsManagerLoader(c.secrets, &Options{Region: "us-east-1", Endpoint: server.URL, Credentials: testCredentials}))
+
+			err := config.Load()
+			if c.notFound {
+				if !errors.Is(err, cfg.ErrNotFound) {
+					t.Errorf("%v != %v", cfg.ErrNotFound, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			for k, expected := range c.expected {
+				if actual := config.MustGetString(k); actual != expected {
+					t.Errorf("%s %s != %s", k, expected, actual)
+				}
+				if p, _ := config.Provenance(k); !p.Sensitive {
+					t.Errorf("%s is not sensitive", k)
+				}
+			}
+		})
+	}
+}
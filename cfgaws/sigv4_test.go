@@ -0,0 +1,48 @@
+package cfgaws
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_signV4(t *testing.T) {
+	// Test vectors from the AWS Signature Version 4 test suite
+	creds := Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{
+			name: "Vanilla",
+			url:  "https://example.amazonaws.com/",
+			expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name: "Query",
+			url:  "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, c.url, nil)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if err := signV4(req, creds, "us-east-1", "service", now); err != nil {
+				t.Fatalf("%v", err)
+			}
+			if actual := req.Header.Get("Authorization"); actual != c.expected {
+				t.Errorf("%s != %s", c.expected, actual)
+			}
+		})
+	}
+}
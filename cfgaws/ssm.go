@@ -0,0 +1,88 @@
+package cfgaws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/internal/kv"
+)
+
+// Config loader designed to load a hierarchy of parameters from SSM Parameter Store. Parameter names
+// are split on "/" into nested configuration relative to the path e.g. "/app/prod/db/host" under the
+// path "/app/prod" is loaded as "db:host". SecureString parameters are decrypted
+type ParameterStoreLoader struct {
+	path   string
+	client *client
+}
+
+type ssmParameter struct {
+	Name  string
+	Value string
+}
+
+// Loads configuration from a source into a map
+func (loader *ParameterStoreLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// Loads configuration from a source into a map, cancelling requests if the context is done
+func (loader *ParameterStoreLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	pairs := make([]kv.Pair, 0)
+	nextToken := ""
+
+	for {
+		in := map[string]any{
+			"Path":           loader.path,
+			"Recursive":      true,
+			"WithDecryption": true,
+		}
+		if nextToken != "" {
+			in["NextToken"] = nextToken
+		}
+
+		var out struct {
+			Parameters []ssmParameter
+			NextToken  string
+		}
+		apiErr, err := loader.client.call(ctx, "GetParametersByPath", in, &out)
+		if err != nil {
+			return nil, err
+		} else if apiErr != nil {
+			return nil, fmt.Errorf("ssm path %s: %s: %s", loader.path, apiErr.code(), apiErr.Message)
+		}
+
+		for _, p := range out.Parameters {
+			pairs = append(pairs, kv.Pair{Key: p.Name, Value: []byte(p.Value)})
+		}
+
+		if nextToken = out.NextToken; nextToken == "" {
+			break
+		}
+	}
+
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("ssm path %s: %w", loader.path, cfg.ErrNotFound)
+	}
+
+	return kv.Nest(pairs, loader.path, nil)
+}
+
+// Reports that parameter names are used as they are, as they may contain the delimiter
+func (loader *ParameterStoreLoader) LiteralKeys() bool {
+	return true
+}
+
+var _ cfg.Loader = (*ParameterStoreLoader)(nil)
+var _ cfg.ContextLoader = (*ParameterStoreLoader)(nil)
+var _ cfg.LiteralKeysLoader = (*ParameterStoreLoader)(nil)
+
+// Creates a new cfg loader designed to load all parameters under a path from SSM Parameter Store.
+// Uses the standard options if none is provided
+func NewParameterStoreLoader(path string, opts *Options) *ParameterStoreLoader {
+	return &ParameterStoreLoader{
+		path:   "/" + strings.Trim(path, "/"),
+		client: newClient("ssm", "AmazonSSM", opts),
+	}
+}
@@ -0,0 +1,127 @@
+package cfgaws
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jaredhughes1012/cfg"
+)
+
+var testCredentials = StaticCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}
+
+// Fake of an AWS JSON protocol endpoint. Requests must be signed and each operation is handled by a
+// function of its decoded request body
+func newFakeAws(t *testing.T, service string, ops map[string]func(in map[string]any) (int, any)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/"+service+"/aws4_request") {
+			t.Errorf("Invalid authorization %s", auth)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		_, op, _ := strings.Cut(r.Header.Get("X-Amz-Target"), ".")
+		handler, ok := ops[op]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var in map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		status, out := handler(in)
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+}
+
+func Test_ParameterStoreLoader_Load(t *testing.T) {
+	parameters := []map[string]any{
+		{"Name": "/app/prod/db/host", "Value": "localhost", "Type": "String"},
+		{"Name": "/app/prod/db/password", "Value": "hunter2", "Type": "SecureString"},
+		{"Name": "/app/prod/name", "Value": "svc", "Type": "String"},
+	}
+
+	server := newFakeAws(t, "ssm", map[string]func(map[string]any) (int, any){
+		"GetParametersByPath": func(in map[string]any) (int, any) {
+			if in["WithDecryption"] != true || in["Recursive"] != true {
+				t.Errorf("Parameters requested without decryption or recursion")
+			}
+			if in["Path"] != "/app/prod" {
+				return http.StatusOK, map[string]any{"Parameters": []any{}}
+			}
+
+			// Return one parameter per page
+			page := 0
+			if token, ok := in["NextToken"].(string); ok {
+				page, _ = strconv.Atoi(token)
+			}
+			out := map[string]any{"Parameters": parameters[page : page+1]}
+			if page+1 < len(parameters) {
+				out["NextToken"] = strconv.Itoa(page + 1)
+			}
+			return http.StatusOK, out
+		},
+	})
+	defer server.Close()
+
+	cases := []struct {
+		name     string
+		path     string
+		expected map[string]string
+		notFound bool
+	}{
+		{
+			name:     "Nested parameters",
+			path:     "/app/prod/",
+			expected: map[string]string{"db:host": "localhost", "db:password": "hunter2", "name": "svc"},
+		},
+		{
+			name:     "Path not found",
+			path:     "/app/dev",
+			notFound: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := cfg.New()
+			config.Add(NewParameterStoreLoader(c.path, &Options{Region: "us-east-1", Endpoint: server.URL, Credentials: testCredentials}))
+
+			err := config.Load()
+			if c.notFound {
+				if !errors.Is(err, cfg.ErrNotFound) {
+					t.Errorf("%v != %v", cfg.ErrNotFound, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			for k, expected := range c.expected {
+				if actual := config.MustGetString(k); actual != expected {
+					t.Errorf("%s %s != %s", k, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func Test_ParameterStoreLoader_Load_Error(t *testing.T) {
+	server := newFakeAws(t, "ssm", map[string]func(map[string]any) (int, any){
+		"GetParametersByPath": func(in map[string]any) (int, any) {
+			return http.StatusBadRequest, map[string]any{"__type": "AccessDeniedException", "message": "denied"}
+		},
+	})
+	defer server.Close()
+
+	_, err := NewParameterStoreLoader("/app", &Options{Region: "us-east-1", Endpoint: server.URL, Credentials: testCredentials}).Load()
+	if err == nil || !strings.Contains(err.Error(), "AccessDeniedException") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
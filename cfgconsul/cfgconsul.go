@@ -0,0 +1,200 @@
+package cfgconsul
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/internal/kv"
+)
+
+// Special options used to control how configuration is read from Consul
+type Options struct {
+	// Address of the Consul HTTP API
+	Address string
+
+	// If set, sent as the ACL token of every request
+	Token string
+
+	// If set, reads from this datacenter instead of the datacenter of the agent
+	Datacenter string
+
+	// If set, used for requests instead of the default client. Should not have a timeout shorter
+	// than WaitTime, or blocking queries will fail
+	Client *http.Client
+
+	// Decoders by key extension e.g. ".json". Values of keys with a listed extension are decoded and
+	// nested under the key without its extension. Values of all other keys are loaded as strings
+	Decoders map[string]cfg.Decoder
+
+	// Maximum time a blocking query waits for a change before it is repeated
+	WaitTime time.Duration
+}
+
+// Standard options that are used if none is provided
+var StandardOptions = Options{
+	Address:  "http://127.0.0.1:8500",
+	WaitTime: 5 * time.Minute,
+}
+
+// Config loader designed to load all keys under a prefix from the Consul KV store. Keys are split
+// on "/" into nested configuration e.g. "db/host" under the prefix is loaded as "db:host"
+type ConsulLoader struct {
+	prefix string
+	opts   *Options
+	client *http.Client
+
+	mu    sync.Mutex
+	index uint64
+}
+
+// Loads configuration from a source into a map
+func (loader *ConsulLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// Loads configuration from a source into a map, cancelling the request if the context is done
+func (loader *ConsulLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	resp, err := loader.get(ctx, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("consul prefix %s: %w", loader.prefix, cfg.ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("consul prefix %s: unexpected status %s", loader.prefix, resp.Status)
+	}
+
+	var pairs []kv.Pair
+	if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, fmt.Errorf("consul prefix %s: %w", loader.prefix, err)
+	}
+
+	data, err := kv.Nest(pairs, strings.TrimPrefix(loader.prefix, "/"), loader.opts.Decoders)
+	if err != nil {
+		return nil, fmt.Errorf("consul prefix %s: %w", loader.prefix, err)
+	}
+
+	if index, ok := consulIndex(resp); ok {
+		loader.mu.Lock()
+		loader.index = index
+		loader.mu.Unlock()
+	}
+
+	return data, nil
+}
+
+// Blocks until any key under the prefix changes, using Consul blocking queries
+func (loader *ConsulLoader) Watch(ctx context.Context) error {
+	for {
+		loader.mu.Lock()
+		index := loader.index
+		loader.mu.Unlock()
+
+		query := url.Values{}
+		if index > 0 {
+			query.Set("index", strconv.FormatUint(index, 10))
+			query.Set("wait", loader.opts.WaitTime.String())
+		}
+
+		resp, err := loader.get(ctx, query)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		// A missing prefix is still watched, as keys may be created under it
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+			return fmt.Errorf("consul prefix %s: unexpected status %s", loader.prefix, resp.Status)
+		}
+
+		next, ok := consulIndex(resp)
+		if !ok {
+			return fmt.Errorf("consul prefix %s: response has no index", loader.prefix)
+		}
+
+		loader.mu.Lock()
+		loader.index = next
+		loader.mu.Unlock()
+
+		// The index may go backwards if the Consul state is restored, in which case it is treated as
+		// a change. Waits that time out return the same index
+		if index > 0 && next != index {
+			return nil
+		}
+	}
+}
+
+func (loader *ConsulLoader) get(ctx context.Context, query url.Values) (*http.Response, error) {
+	query.Set("recurse", "true")
+	if loader.opts.Datacenter != "" {
+		query.Set("dc", loader.opts.Datacenter)
+	}
+
+	u := fmt.Sprintf("%s/v1/kv/%s?%s", strings.TrimSuffix(loader.opts.Address, "/"),
+		strings.TrimPrefix(loader.prefix, "/"), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if loader.opts.Token != "" {
+		req.Header.Set("X-Consul-Token", loader.opts.Token)
+	}
+
+	return loader.client.Do(req)
+}
+
+func consulIndex(resp *http.Response) (uint64, bool) {
+	index, err := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	return index, err == nil
+}
+
+// Reports that keys in Consul are used as they are, as they may contain the delimiter
+func (loader *ConsulLoader) LiteralKeys() bool {
+	return true
+}
+
+var _ cfg.Loader = (*ConsulLoader)(nil)
+var _ cfg.ContextLoader = (*ConsulLoader)(nil)
+var _ cfg.Watcher = (*ConsulLoader)(nil)
+var _ cfg.LiteralKeysLoader = (*ConsulLoader)(nil)
+
+// Creates a new cfg loader designed to load all keys under a prefix from the Consul KV store. Uses
+// the standard options if none is provided
+func NewLoader(prefix string, opts *Options) *ConsulLoader {
+	if opts == nil {
+		opts = &StandardOptions
+	}
+	if opts.Address == "" || opts.WaitTime <= 0 {
+		o := *opts
+		if o.Address == "" {
+			o.Address = StandardOptions.Address
+		}
+		if o.WaitTime <= 0 {
+			o.WaitTime = StandardOptions.WaitTime
+		}
+		opts = &o
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	return &ConsulLoader{
+		prefix: prefix,
+		opts:   opts,
+		client: client,
+	}
+}
@@ -0,0 +1,212 @@
+package cfgconsul
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/cfgjson"
+	"github.com/jaredhughes1012/cfg/internal/kv"
+)
+
+// Fake of the Consul KV endpoints, supporting recursive reads and blocking queries
+type fakeConsul struct {
+	mu      sync.Mutex
+	kv      map[string]string
+	index   uint64
+	changed chan struct{}
+	token   string
+}
+
+func newFakeConsul(kv map[string]string) *fakeConsul {
+	return &fakeConsul{kv: kv, index: 1, changed: make(chan struct{})}
+}
+
+func (f *fakeConsul) set(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.kv[key] = value
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.token != "" && r.Header.Get("X-Consul-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	f.mu.Lock()
+	index, changed := f.index, f.changed
+	f.mu.Unlock()
+
+	if wait, err := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); err == nil && wait == index {
+		select {
+		case <-changed:
+		case <-time.After(50 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pairs := make([]kv.Pair, 0)
+	for k, v := range f.kv {
+		if strings.HasPrefix(k, prefix) {
+			pairs = append(pairs, kv.Pair{Key: k, Value: []byte(v)})
+		}
+	}
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+func Test_ConsulLoader_Load(t *testing.T) {
+	cases := []struct {
+		name     string
+		prefix   string
+		kv       map[string]string
+		opts     Options
+		key      string
+		expected string
+		isErr    bool
+		notFound bool
+	}{
+		{
+			name:     "Nested key",
+			prefix:   "app/",
+			kv:       map[string]string{"app/db/host": "localhost", "app/": ""},
+			key:      "db:host",
+			expected: "localhost",
+		},
+		{
+			name:     "Prefix without slash",
+			prefix:   "app",
+			kv:       map[string]string{"app/db/host": "localhost"},
+			key:      "db:host",
+			expected: "localhost",
+		},
+		{
+			name:     "Decoded blob",
+			prefix:   "app/",
+			kv:       map[string]string{"app/db.json": `{"host": "localhost"}`, "app/db/port": "5432"},
+			opts:     Options{Decoders: map[string]cfg.Decoder{".json": cfgjson.Decode}},
+			key:      "db:host",
+			expected: "localhost",
+		},
+		{
+			name:     "Blob without decoder",
+			prefix:   "app/",
+			kv:       map[string]string{"app/db.json": `{"host": "localhost"}`},
+			key:      "db.json",
+			expected: `{"host": "localhost"}`,
+		},
+		{
+			name:   "Invalid blob",
+			prefix: "app/",
+			kv:     map[string]string{"app/db.json": `{`},
+			opts:   Options{Decoders: map[string]cfg.Decoder{".json": cfgjson.Decode}},
+			isErr:  true,
+		},
+		{
+			name:     "Prefix not found",
+			prefix:   "other/",
+			kv:       map[string]string{"app/db/host": "localhost"},
+			isErr:    true,
+			notFound: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(newFakeConsul(c.kv))
+			defer server.Close()
+
+			c.opts.Address = server.URL
+			config := cfg.New()
+			config.Add(NewLoader(c.prefix, &c.opts))
+
+			err := config.Load()
+			if c.isErr {
+				if err == nil {
+					t.Fatal("No error when error expected")
+				} else if c.notFound != errors.Is(err, cfg.ErrNotFound) {
+					t.Errorf("Not found %v != %v", c.notFound, errors.Is(err, cfg.ErrNotFound))
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if actual := config.MustGetString(c.key); actual != c.expected {
+				t.Errorf("%s != %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_ConsulLoader_Load_Token(t *testing.T) {
+	fake := newFakeConsul(map[string]string{"app/key": "val"})
+	fake.token = "secret"
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	if _, err := NewLoader("app/", &Options{Address: server.URL}).Load(); err == nil {
+		t.Error("No error when error expected")
+	}
+	if _, err := NewLoader("app/", &Options{Address: server.URL, Token: "secret"}).Load(); err != nil {
+		t.Errorf("%v", err)
+	}
+}
+
+func Test_ConsulLoader_Watch(t *testing.T) {
+	fake := newFakeConsul(map[string]string{"app/key": "old"})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	config := cfg.New()
+	config.Add(NewLoader("app/", &Options{Address: server.URL}))
+	if err := config.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reloads := make(chan error, 1)
+	go func() {
+		_ = config.Watch(ctx, func(err error) { reloads <- err })
+	}()
+
+	// Let the watch wait through at least one timed out query before changing the key
+	time.Sleep(100 * time.Millisecond)
+	fake.set("app/key", "new")
+
+	select {
+	case err := <-reloads:
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for reload")
+	}
+
+	if actual := config.MustGetString("key"); actual != "new" {
+		t.Errorf("new != %s", actual)
+	}
+}
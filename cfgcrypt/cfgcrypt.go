@@ -0,0 +1,439 @@
+// Package cfgcrypt loads configuration that is encrypted at rest. Documents may have their values
+// encrypted with AES-256-GCM, keeping keys in clear text so that changes can be reviewed, along with
+// a "cfgcrypt" section holding an encrypted MAC of the document. The format is similar to SOPS but
+// is not compatible with it, so files encrypted by sops must be decrypted with sops instead. Whole
+// files encrypted with age are detected, but decrypting them is left to the caller, which provides
+// an age implementation through Options.Decrypter
+package cfgcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	encPrefix = "ENC[AES256_GCM,"
+	encSuffix = "]"
+
+	// Key of the section holding encryption metadata in encrypted documents
+	metadataKey = "cfgcrypt"
+
+	// Key of the metadata section in documents encrypted by sops, which are not supported
+	sopsKey = "sops"
+)
+
+// Key path of the encrypted MAC in the metadata section
+var macPath = []string{metadataKey, "mac"}
+
+// Gets the 256 bit data key used to encrypt values. Documents may name their key in the "key_id"
+// field of their "cfgcrypt" section, otherwise the ID is empty
+type KeyProvider func(keyID string) ([]byte, error)
+
+// Provides the same key for every document
+func StaticKey(key []byte) KeyProvider {
+	return func(string) ([]byte, error) {
+		return key, nil
+	}
+}
+
+// Provides a base64 encoded key read from an environment variable
+func EnvKey(name string) KeyProvider {
+	return func(string) ([]byte, error) {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", name)
+		}
+
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(v))
+	}
+}
+
+// Provides a base64 encoded key read from a file, such as a mounted secret
+func KeyFile(path string) KeyProvider {
+	return func(string) ([]byte, error) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+	}
+}
+
+// Gets the additional data authenticated with a value, which binds it to its key path so that
+// encrypted values cannot be moved between keys
+func additionalData(path []string) []byte {
+	return []byte(strings.Join(path, ":") + ":")
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("data key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Encrypts a value stored at the given key path e.g. ["db", "password"]. Strings, ints, float64s
+// and bools are supported. The result has the form ENC[AES256_GCM,data:...,iv:...,tag:...,type:...].
+// Documents with encrypted values also need a MAC to be decrypted, so use EncryptDocument to
+// encrypt a whole document
+func EncryptValue(path []string, v any, key []byte) (string, error) {
+	var plain, typ string
+	switch vt := v.(type) {
+	case string:
+		plain, typ = vt, "str"
+	case int:
+		plain, typ = strconv.Itoa(vt), "int"
+	case float64:
+		plain, typ = strconv.FormatFloat(vt, 'g', -1, 64), "float"
+	case bool:
+		plain, typ = strconv.FormatBool(vt), "bool"
+	default:
+		return "", fmt.Errorf("cannot encrypt value of type %T", v)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nil, iv, []byte(plain), additionalData(path))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	enc := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("%sdata:%s,iv:%s,tag:%s,type:%s%s", encPrefix, enc(data), enc(iv), enc(tag), typ, encSuffix), nil
+}
+
+func isEncrypted(v any) bool {
+	s, ok := v.(string)
+	return ok && strings.HasPrefix(s, encPrefix) && strings.HasSuffix(s, encSuffix)
+}
+
+// Decrypts a value produced by EncryptValue for the same key path
+func decryptValue(path []string, s string, gcm cipher.AEAD) (any, error) {
+	fields := make(map[string]string)
+	for _, field := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(s, encPrefix), encSuffix), ",") {
+		name, value, _ := strings.Cut(field, ":")
+		fields[name] = value
+	}
+
+	parts := make(map[string][]byte)
+	for _, name := range []string{"data", "iv", "tag"} {
+		b, err := base64.StdEncoding.DecodeString(fields[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		parts[name] = b
+	}
+	if len(parts["iv"]) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid iv length %d", len(parts["iv"]))
+	}
+
+	plain, err := gcm.Open(nil, parts["iv"], append(parts["data"], parts["tag"]...), additionalData(path))
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", err)
+	}
+
+	switch fields["type"] {
+	case "str", "":
+		return string(plain), nil
+	case "int":
+		return strconv.Atoi(string(plain))
+	case "float":
+		return strconv.ParseFloat(string(plain), 64)
+	case "bool":
+		return strconv.ParseBool(string(plain))
+	default:
+		return nil, fmt.Errorf("unknown type %s", fields["type"])
+	}
+}
+
+// Encrypts the values of a document whose key paths are matched by encrypt, or every value if
+// encrypt is nil, and adds a "cfgcrypt" section naming the key and holding an encrypted MAC of
+// every value in the document. The MAC is verified when the document is decrypted, so values cannot
+// be removed, added or replaced with plain text without the key
+func EncryptDocument(data map[string]any, keyID string, key []byte, encrypt func(path []string) bool) (map[string]any, error) {
+	mac, err := EncryptValue(macPath, documentMAC(data), key)
+	if err != nil {
+		return nil, err
+	}
+
+	var walk func(v any, path []string) (any, error)
+	walk = func(v any, path []string) (any, error) {
+		switch vt := v.(type) {
+		case map[string]any:
+			out := make(map[string]any, len(vt))
+			for k, child := range vt {
+				if len(path) == 0 && k == metadataKey {
+					continue
+				}
+
+				enc, err := walk(child, append(append([]string{}, path...), k))
+				if err != nil {
+					return nil, err
+				}
+				out[k] = enc
+			}
+			return out, nil
+		case []any:
+			out := make([]any, len(vt))
+			for i, child := range vt {
+				enc, err := walk(child, append(append([]string{}, path...), strconv.Itoa(i)))
+				if err != nil {
+					return nil, err
+				}
+				out[i] = enc
+			}
+			return out, nil
+		}
+
+		if v == nil || (encrypt != nil && !encrypt(path)) {
+			return v, nil
+		}
+
+		enc, err := EncryptValue(path, v, key)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.Join(path, ":"), err)
+		}
+		return enc, nil
+	}
+
+	out, err := walk(data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := out.(map[string]any)
+	doc[metadataKey] = map[string]any{"key_id": keyID, "mac": mac}
+	return doc, nil
+}
+
+// Gets a hex encoded hash of every plain text value in a document and its key path, ignoring the
+// "cfgcrypt" section. Numbers are hashed by value, so documents hash the same whether they were
+// decoded with ints or float64s
+func documentMAC(data map[string]any) string {
+	entries := make([]string, 0)
+
+	var walk func(v any, path []string)
+	walk = func(v any, path []string) {
+		switch vt := v.(type) {
+		case map[string]any:
+			for k, child := range vt {
+				if len(path) > 0 || k != metadataKey {
+					walk(child, append(append([]string{}, path...), k))
+				}
+			}
+			return
+		case []any:
+			for i, child := range vt {
+				walk(child, append(append([]string{}, path...), strconv.Itoa(i)))
+			}
+			return
+		}
+
+		// Length prefixes keep distinct paths and values from producing the same entry
+		var sb strings.Builder
+		for _, seg := range path {
+			fmt.Fprintf(&sb, "%d:%s", len(seg), seg)
+		}
+		value := canonicalValue(v)
+		fmt.Fprintf(&sb, "=%d:%s", len(value), value)
+		entries = append(entries, sb.String())
+	}
+	walk(data, nil)
+
+	sort.Strings(entries)
+	hash := sha256.New()
+	for _, entry := range entries {
+		fmt.Fprintf(hash, "%d:%s", len(entry), entry)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Gets the form of a value used in the document MAC, tagged with the kind of value
+func canonicalValue(v any) string {
+	if v == nil {
+		return "null"
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return "s:" + rv.String()
+	case reflect.Bool:
+		return "b:" + strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "n:" + strconv.FormatFloat(float64(rv.Int()), 'g', -1, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "n:" + strconv.FormatFloat(float64(rv.Uint()), 'g', -1, 64)
+	case reflect.Float32, reflect.Float64:
+		return "n:" + strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+	default:
+		return fmt.Sprintf("v:%v", v)
+	}
+}
+
+// Gets a copy of the map with all encrypted values decrypted, recursing into nested maps and
+// arrays, and the "cfgcrypt" metadata section removed. Reports whether any values were encrypted. The
+// key provider is only called if the map contains encrypted values or metadata. Documents with
+// either must have a MAC in their metadata matching the decrypted values, otherwise an error is
+// returned
+func decryptMap(data map[string]any, keys KeyProvider) (map[string]any, bool, error) {
+	if isSOPS(data) {
+		return nil, false, fmt.Errorf("document is encrypted with sops, which is not supported; decrypt it with sops before loading it")
+	}
+
+	meta, hasMeta := data[metadataKey].(map[string]any)
+	keyID, _ := meta["key_id"].(string)
+
+	d := &decrypter{keyID: keyID, keys: keys}
+	out := make(map[string]any, len(data))
+	for k, v := range data {
+		if k == metadataKey {
+			continue
+		}
+
+		plain, err := d.decrypt(v, []string{k})
+		if err != nil {
+			return nil, false, err
+		}
+		out[k] = plain
+	}
+
+	if hasMeta || d.encrypted {
+		if err := d.verify(meta["mac"], out); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return out, d.encrypted, nil
+}
+
+// Reports whether a document was encrypted by sops, which adds a "sops" section with an encrypted
+// MAC and the version of sops. Other sections named "sops" are left alone
+func isSOPS(data map[string]any) bool {
+	meta, ok := data[sopsKey].(map[string]any)
+	if !ok || !isEncrypted(meta["mac"]) {
+		return false
+	}
+
+	_, hasVersion := meta["version"]
+	_, hasModified := meta["lastmodified"]
+	return hasVersion || hasModified
+}
+
+// Decrypts values using a data key that is only requested once it is needed
+type decrypter struct {
+	keyID string
+	keys  KeyProvider
+	gcm   cipher.AEAD
+
+	// Set once any encrypted value has been decrypted
+	encrypted bool
+}
+
+// Gets the cipher for the data key, requesting the key on first use
+func (d *decrypter) cipher() (cipher.AEAD, error) {
+	if d.gcm != nil {
+		return d.gcm, nil
+	} else if d.keys == nil {
+		return nil, fmt.Errorf("document is encrypted but no key provider is configured")
+	}
+
+	key, err := d.keys(d.keyID)
+	if err != nil {
+		return nil, fmt.Errorf("data key %q: %w", d.keyID, err)
+	}
+
+	d.gcm, err = newGCM(key)
+	return d.gcm, err
+}
+
+// Checks that the MAC from the metadata section matches the decrypted document
+func (d *decrypter) verify(mac any, data map[string]any) error {
+	if !isEncrypted(mac) {
+		return fmt.Errorf("document is encrypted but has no MAC")
+	}
+
+	gcm, err := d.cipher()
+	if err != nil {
+		return err
+	}
+
+	expected, err := decryptValue(macPath, mac.(string), gcm)
+	if err != nil {
+		return fmt.Errorf("MAC: %w", err)
+	}
+
+	s, _ := expected.(string)
+	if subtle.ConstantTimeCompare([]byte(s), []byte(documentMAC(data))) != 1 {
+		return fmt.Errorf("MAC mismatch, document has been modified")
+	}
+
+	return nil
+}
+
+func (d *decrypter) decrypt(v any, path []string) (any, error) {
+	switch vt := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(vt))
+		for k, child := range vt {
+			plain, err := d.decrypt(child, append(append([]string{}, path...), k))
+			if err != nil {
+				return nil, err
+			}
+			out[k] = plain
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(vt))
+		for i, child := range vt {
+			plain, err := d.decrypt(child, append(append([]string{}, path...), strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = plain
+		}
+		return out, nil
+	}
+
+	if !isEncrypted(v) {
+		return v, nil
+	}
+
+	gcm, err := d.cipher()
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := decryptValue(path, v.(string), gcm)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.Join(path, ":"), err)
+	}
+
+	d.encrypted = true
+	return plain, nil
+}
@@ -0,0 +1,195 @@
+package cfgcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testKey = bytes.Repeat([]byte{7}, 32)
+
+func mustEncryptDocument(t *testing.T, data map[string]any, encrypt func(path []string) bool) map[string]any {
+	doc, err := EncryptDocument(data, "", testKey, encrypt)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	return doc
+}
+
+func Test_decryptMap(t *testing.T) {
+	plain := map[string]any{
+		"db":      map[string]any{"password": "hunter2", "port": 5432, "host": "localhost"},
+		"ratio":   0.5,
+		"enabled": true,
+		"list":    []any{"a"},
+	}
+	notHost := func(path []string) bool {
+		return path[len(path)-1] != "host"
+	}
+
+	cases := []struct {
+		name      string
+		data      func(t *testing.T) map[string]any
+		keys      KeyProvider
+		expected  map[string]any
+		encrypted bool
+		isErr     bool
+	}{
+		{
+			name: "Typed values",
+			data: func(t *testing.T) map[string]any {
+				doc, err := EncryptDocument(plain, "prod", testKey, notHost)
+				if err != nil {
+					t.Fatalf("%v", err)
+				}
+				return doc
+			},
+			keys: func(id string) ([]byte, error) {
+				if id != "prod" {
+					t.Errorf("prod != %s", id)
+				}
+				return testKey, nil
+			},
+			expected:  plain,
+			encrypted: true,
+		},
+		{
+			name:     "No encrypted values",
+			data:     func(t *testing.T) map[string]any { return map[string]any{"name": "svc"} },
+			expected: map[string]any{"name": "svc"},
+		},
+		{
+			name:     "Unrelated sops section",
+			data:     func(t *testing.T) map[string]any { return map[string]any{"sops": map[string]any{"enabled": true}} },
+			expected: map[string]any{"sops": map[string]any{"enabled": true}},
+		},
+		{
+			name: "Value moved to another key",
+			data: func(t *testing.T) map[string]any {
+				doc := mustEncryptDocument(t, map[string]any{"public": "svc", "private": "hunter2"}, nil)
+				doc["public"] = doc["private"]
+				return doc
+			},
+			keys:  StaticKey(testKey),
+			isErr: true,
+		},
+		{
+			name: "Value removed",
+			data: func(t *testing.T) map[string]any {
+				doc := mustEncryptDocument(t, plain, notHost)
+				delete(doc, "ratio")
+				return doc
+			},
+			keys:  StaticKey(testKey),
+			isErr: true,
+		},
+		{
+			name: "Value replaced with plain text",
+			data: func(t *testing.T) map[string]any {
+				doc := mustEncryptDocument(t, plain, notHost)
+				doc["enabled"] = false
+				return doc
+			},
+			keys:  StaticKey(testKey),
+			isErr: true,
+		},
+		{
+			name: "Plain value modified",
+			data: func(t *testing.T) map[string]any {
+				doc := mustEncryptDocument(t, plain, notHost)
+				doc["db"].(map[string]any)["host"] = "attacker"
+				return doc
+			},
+			keys:  StaticKey(testKey),
+			isErr: true,
+		},
+		{
+			name: "All values replaced with plain text",
+			data: func(t *testing.T) map[string]any {
+				doc := mustEncryptDocument(t, map[string]any{"password": "hunter2"}, nil)
+				doc["password"] = "attacker"
+				return doc
+			},
+			keys:  StaticKey(testKey),
+			isErr: true,
+		},
+		{
+			name: "No MAC",
+			data: func(t *testing.T) map[string]any {
+				doc := mustEncryptDocument(t, map[string]any{"password": "hunter2"}, nil)
+				delete(doc, metadataKey)
+				return doc
+			},
+			keys:  StaticKey(testKey),
+			isErr: true,
+		},
+		{
+			name: "Wrong key",
+			data: func(t *testing.T) map[string]any {
+				return mustEncryptDocument(t, map[string]any{"password": "hunter2"}, nil)
+			},
+			keys:  StaticKey(bytes.Repeat([]byte{8}, 32)),
+			isErr: true,
+		},
+		{
+			name: "No key provider",
+			data: func(t *testing.T) map[string]any {
+				return mustEncryptDocument(t, map[string]any{"password": "hunter2"}, nil)
+			},
+			isErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := c.data(t)
+			actual, encrypted, err := decryptMap(data, c.keys)
+			if c.isErr {
+				if err == nil {
+					t.Error("No error when error expected")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("%v != %v", c.expected, actual)
+			}
+			if c.encrypted != encrypted {
+				t.Errorf("Encrypted %v != %v", c.encrypted, encrypted)
+			}
+		})
+	}
+}
+
+func Test_decryptMap_SOPS(t *testing.T) {
+	doc := mustEncryptDocument(t, map[string]any{"password": "hunter2"}, nil)
+	doc["sops"] = map[string]any{"mac": doc[metadataKey].(map[string]any)["mac"], "version": "3.8.1"}
+	delete(doc, metadataKey)
+
+	_, _, err := decryptMap(doc, StaticKey(testKey))
+	if err == nil || !strings.Contains(err.Error(), "encrypted with sops") {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func Test_KeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(testKey)+"\n"), 0600); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	key, err := KeyFile(path)("")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(testKey, key) {
+		t.Errorf("%v != %v", testKey, key)
+	}
+}
@@ -0,0 +1,207 @@
+package cfgcrypt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/cfgjson"
+)
+
+// Headers that begin age encrypted files, in binary and armored form
+var ageHeaders = [][]byte{
+	[]byte("age-encryption.org/v1\n"),
+	[]byte("-----BEGIN AGE ENCRYPTED FILE-----"),
+}
+
+// Decrypts a whole encrypted file, such as an age file. Typically wraps an age library with the
+// identities of the deployment e.g. age.Decrypt(r, identities...)
+type Decrypter func(r io.Reader) (io.Reader, error)
+
+// Special options used to control how encrypted configuration is decrypted
+type Options struct {
+	// Provides data keys for documents whose values are encrypted
+	Keys KeyProvider
+
+	// Decrypts whole files that begin with an age header. Files without the header are read as is
+	Decrypter Decrypter
+
+	// Decoders by file extension e.g. ".yaml", used by NewFileLoader. Files with an extension that
+	// is not listed are decoded as JSON
+	Decoders map[string]cfg.Decoder
+}
+
+// Reports whether a document is encrypted with age
+func isAge(r *bufio.Reader) bool {
+	for _, header := range ageHeaders {
+		if b, _ := r.Peek(len(header)); bytes.Equal(b, header) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Wraps a decoder so that documents encrypted with age are decrypted before they are decoded and
+// encrypted values are decrypted after, so that encrypted documents can be read by any loader that
+// accepts a decoder, such as cfghttp or cfgconsul
+func Decoder(decode cfg.Decoder, opts *Options) cfg.Decoder {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	return func(r io.Reader) (map[string]any, error) {
+		data, _, err := decodeEncrypted(r, decode, opts)
+		return data, err
+	}
+}
+
+// Decodes a document that may be encrypted, reporting whether any part of it was encrypted
+func decodeEncrypted(r io.Reader, decode cfg.Decoder, opts *Options) (map[string]any, bool, error) {
+	br := bufio.NewReader(r)
+	var in io.Reader = br
+	wholeFile := isAge(br)
+
+	if wholeFile {
+		if opts.Decrypter == nil {
+			return nil, false, fmt.Errorf("document is encrypted with age but no decrypter is configured")
+		}
+
+		var err error
+		if in, err = opts.Decrypter(br); err != nil {
+			return nil, false, fmt.Errorf("age decryption failed: %w", err)
+		}
+	}
+
+	data, err := decode(in)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, encrypted, err := decryptMap(data, opts.Keys)
+	return data, encrypted || wholeFile, err
+}
+
+// Config loader designed to load encrypted files. Files may be encrypted as a whole with age or
+// have their values encrypted with EncryptDocument, with keys left in clear text so that changes can
+// be reviewed. Files without encryption are loaded as is
+type FileLoader struct {
+	path     string
+	required bool
+	opts     *Options
+
+	encrypted atomic.Bool
+}
+
+// Loads configuration from a source into a map
+func (loader *FileLoader) Load() (map[string]any, error) {
+	f, err := os.Open(loader.path)
+	if err != nil {
+		if !loader.required {
+			return map[string]any{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	decode, ok := loader.opts.Decoders[filepath.Ext(loader.path)]
+	if !ok {
+		decode = cfgjson.Decode
+	}
+
+	data, encrypted, err := decodeEncrypted(f, decode, loader.opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", loader.path, err)
+	}
+
+	loader.encrypted.Store(encrypted)
+	return data, nil
+}
+
+// Reports whether the file was encrypted when it was last loaded, as its values are then secrets
+func (loader *FileLoader) Sensitive() bool {
+	return loader.encrypted.Load()
+}
+
+// Reports that keys in decrypted documents are used as they are, as they may contain the delimiter
+func (loader *FileLoader) LiteralKeys() bool {
+	return true
+}
+
+var _ cfg.Loader = (*FileLoader)(nil)
+var _ cfg.SensitiveLoader = (*FileLoader)(nil)
+var _ cfg.LiteralKeysLoader = (*FileLoader)(nil)
+
+// Creates a new cfg loader designed to load a file that may be encrypted. If required is false, a
+// missing file loads no values
+func NewFileLoader(path string, required bool, opts *Options) *FileLoader {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	return &FileLoader{
+		path:     path,
+		required: required,
+		opts:     opts,
+	}
+}
+
+type decryptLoader struct {
+	loader cfg.Loader
+	keys   KeyProvider
+
+	encrypted atomic.Bool
+}
+
+// Loads configuration from the wrapped loader and decrypts its encrypted values
+func (l *decryptLoader) Load() (map[string]any, error) {
+	return l.LoadContext(context.Background())
+}
+
+// Loads configuration from the wrapped loader, passing it the context, and decrypts its encrypted
+// values
+func (l *decryptLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	var data map[string]any
+	var err error
+	if cl, ok := l.loader.(cfg.ContextLoader); ok {
+		data, err = cl.LoadContext(ctx)
+	} else {
+		data, err = l.loader.Load()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, encrypted, err := decryptMap(data, l.keys)
+	if err != nil {
+		return nil, err
+	}
+
+	l.encrypted.Store(encrypted)
+	return data, nil
+}
+
+// Reports whether any values were encrypted when last loaded
+func (l *decryptLoader) Sensitive() bool {
+	return l.encrypted.Load()
+}
+
+// Gets the wrapped loader
+func (l *decryptLoader) Unwrap() cfg.Loader {
+	return l.loader
+}
+
+// Wraps a loader so that any encrypted values it loads are decrypted, such as encrypted
+// values stored in a JSON file, a git repository or a key-value store
+func Wrap(l cfg.Loader, keys KeyProvider) cfg.Loader {
+	return &decryptLoader{loader: l, keys: keys}
+}
+
+var _ cfg.ContextLoader = (*decryptLoader)(nil)
+var _ cfg.SensitiveLoader = (*decryptLoader)(nil)
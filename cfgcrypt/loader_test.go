@@ -0,0 +1,137 @@
+package cfgcrypt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/cfgjson"
+)
+
+// Stands in for age in tests. Files are the age header followed by the base64 encoded document
+func testDecrypter(r io.Reader) (io.Reader, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(string(b), "age-encryption.org/v1\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.NewReader(string(plain)), nil
+}
+
+func writeTestFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	return path
+}
+
+func Test_FileLoader_Load(t *testing.T) {
+	encrypted, err := json.Marshal(mustEncryptDocument(t, map[string]any{
+		"db": map[string]any{"host": "localhost", "password": "hunter2"},
+	}, func(path []string) bool {
+		return path[len(path)-1] == "password"
+	}))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	plain := `{"db": {"host": "localhost", "password": "hunter2"}}`
+	age := "age-encryption.org/v1\n" + base64.StdEncoding.EncodeToString([]byte(plain))
+
+	cases := []struct {
+		name      string
+		content   string
+		opts      *Options
+		sensitive bool
+		isErr     bool
+	}{
+		{
+			name:      "Encrypted values",
+			content:   string(encrypted),
+			opts:      &Options{Keys: StaticKey(testKey)},
+			sensitive: true,
+		},
+		{
+			name:      "Whole file",
+			content:   age,
+			opts:      &Options{Decrypter: testDecrypter},
+			sensitive: true,
+		},
+		{
+			name:    "Plain file",
+			content: plain,
+		},
+		{
+			name:    "Whole file without decrypter",
+			content: age,
+			isErr:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := cfg.New()
+			config.Add(NewFileLoader(writeTestFile(t, c.content), true, c.opts))
+
+			err := config.Load()
+			if c.isErr {
+				if err == nil {
+					t.Error("No error when error expected")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if actual := config.MustGetString("db:password"); actual != "hunter2" {
+				t.Errorf("hunter2 != %s", actual)
+			}
+			if p, _ := config.Provenance("db:host"); p.Sensitive != c.sensitive {
+				t.Errorf("Sensitive %v != %v", c.sensitive, p.Sensitive)
+			}
+		})
+	}
+}
+
+func Test_Wrap(t *testing.T) {
+	content, err := json.Marshal(mustEncryptDocument(t, map[string]any{"password": "hunter2"}, nil))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	config := cfg.New()
+	config.Add(Wrap(cfgjson.NewLoader(writeTestFile(t, string(content)), true), StaticKey(testKey)))
+	if err := config.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if actual := config.MustGetString("password"); actual != "hunter2" {
+		t.Errorf("hunter2 != %s", actual)
+	}
+	if p, _ := config.Provenance("password"); !p.Sensitive {
+		t.Error("password is not sensitive")
+	}
+}
+
+func Test_Decoder(t *testing.T) {
+	age := "age-encryption.org/v1\n" + base64.StdEncoding.EncodeToString([]byte(`{"name": "svc"}`))
+	data, err := Decoder(cfgjson.Decode, &Options{Decrypter: testDecrypter})(strings.NewReader(age))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if data["name"] != "svc" {
+		t.Errorf("svc != %v", data["name"])
+	}
+}
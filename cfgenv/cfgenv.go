@@ -47,6 +47,19 @@ func varToMap(key, val, delim string) object {
 	return mroot
 }
 
+// Reports whether every segment of a variable's key has a name. Variables such as "_" or
+// "__CF_USER_TEXT_ENCODING" that are set by shells and operating systems have segments that are
+// empty or only underscores, and are not configuration
+func isConfigKey(key, delim string) bool {
+	for _, seg := range strings.Split(key, delim) {
+		if strings.Trim(seg, "_") == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
 func prepareVar(prefix, envVar string) (string, string) {
 	if len(prefix) > 0 {
 		iPrefix := strings.Index(envVar, prefix)
@@ -69,7 +82,7 @@ func (loader EnvLoader) Load() (map[string]any, error) {
 
 	for _, v := range os.Environ() {
 		key, val := prepareVar(loader.opts.Prefix, v)
-		if key == "" {
+		if key == "" || !isConfigKey(key, loader.opts.Delimiter) {
 			continue
 		}
 
@@ -80,7 +93,20 @@ func (loader EnvLoader) Load() (map[string]any, error) {
 	return data, nil
 }
 
+// Gets the name of the environment variable that sets the value at the given key path
+func (loader EnvLoader) EnvName(path []string) string {
+	return loader.opts.Prefix + strings.ToUpper(strings.Join(path, loader.opts.Delimiter))
+}
+
+// Allows variables that differ only in case, such as HTTP_PROXY and http_proxy, which are commonly
+// set together
+func (loader EnvLoader) AllowDuplicateKeys() bool {
+	return true
+}
+
 var _ cfg.Loader = (*EnvLoader)(nil)
+var _ cfg.EnvNamer = (*EnvLoader)(nil)
+var _ cfg.DuplicateKeysLoader = (*EnvLoader)(nil)
 
 // Creates a new cfg loader designed to load from environment variables
 func NewLoader(opts *Options) *EnvLoader {
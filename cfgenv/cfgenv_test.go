@@ -3,6 +3,8 @@ package cfgenv
 import (
 	"encoding/json"
 	"testing"
+
+	"github.com/jaredhughes1012/cfg"
 )
 
 func compareMaps(t *testing.T, expected, actual map[string]interface{}) {
@@ -125,3 +127,102 @@ func Test_prepareVar(t *testing.T) {
 		})
 	}
 }
+
+func Test_isConfigKey(t *testing.T) {
+	cases := []struct {
+		name     string
+		key      string
+		delim    string
+		expected bool
+	}{
+		{
+			name:     "Nested key",
+			key:      "DB__PORT",
+			delim:    "__",
+			expected: true,
+		},
+		{
+			name:     "Underscore in segment",
+			key:      "DB__MAX_CONNS",
+			delim:    "__",
+			expected: true,
+		},
+		{
+			name:     "Underscore only",
+			key:      "_",
+			delim:    "__",
+			expected: false,
+		},
+		{
+			name:     "Empty segment",
+			key:      "__CF_USER_TEXT_ENCODING",
+			delim:    "__",
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := isConfigKey(c.key, c.delim); c.expected != actual {
+				t.Errorf("%v != %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_EnvLoader_Load(t *testing.T) {
+	t.Setenv("_", "/usr/bin/env")
+	t.Setenv("__CF_USER_TEXT_ENCODING", "0x1F5:0x0:0x0")
+	t.Setenv("CFGENV_TEST__PORT", "8080")
+	t.Setenv("HTTP_PROXY", "http://proxy:3128")
+	t.Setenv("http_proxy", "http://proxy:3128")
+	t.Setenv("NO_PROXY", "localhost")
+	t.Setenv("no_proxy", "localhost")
+
+	config := cfg.New()
+	config.Add(NewLoader(&StandardOptions))
+	if err := config.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if actual := config.MustGetString("cfgenv_test:port"); actual != "8080" {
+		t.Errorf("8080 != %s", actual)
+	}
+	if actual := config.MustGetString("http_proxy"); actual != "http://proxy:3128" {
+		t.Errorf("http://proxy:3128 != %s", actual)
+	}
+	if actual := config.MustGetString("no_proxy"); actual != "localhost" {
+		t.Errorf("localhost != %s", actual)
+	}
+}
+
+func Test_EnvLoader_EnvName(t *testing.T) {
+	cases := []struct {
+		name     string
+		opts     Options
+		path     []string
+		expected string
+	}{
+		{
+			name:     "Standard options",
+			opts:     StandardOptions,
+			path:     []string{"db", "port"},
+			expected: "DB__PORT",
+		},
+		{
+			name:     "Prefix",
+			opts:     Options{Prefix: "APP_", Delimiter: "_"},
+			path:     []string{"db", "port"},
+			expected: "APP_DB_PORT",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := NewLoader(&c.opts).EnvName(c.path)
+			if c.expected != actual {
+				t.Errorf("Name %s != %s", c.expected, actual)
+			}
+		})
+	}
+}
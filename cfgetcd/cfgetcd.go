@@ -0,0 +1,300 @@
+package cfgetcd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/internal/kv"
+)
+
+// Special options used to control how configuration is read from etcd
+type Options struct {
+	// Address of the etcd v3 HTTP gateway
+	Address string
+
+	// If set, used to authenticate before each request
+	Username string
+	Password string
+
+	// If set, used for requests instead of the default client. Should not have a timeout, or
+	// watches will fail
+	Client *http.Client
+
+	// Decoders by key extension e.g. ".json". Values of keys with a listed extension are decoded and
+	// nested under the key without its extension. Values of all other keys are loaded as strings
+	Decoders map[string]cfg.Decoder
+}
+
+// Standard options that are used if none is provided
+var StandardOptions = Options{
+	Address: "http://127.0.0.1:2379",
+}
+
+// Header included in every etcd response. Integers are encoded as strings by the gateway
+type responseHeader struct {
+	Revision string `json:"revision"`
+}
+
+type rangeResponse struct {
+	Header responseHeader `json:"header"`
+	Kvs    []struct {
+		Key   []byte `json:"key"`
+		Value []byte `json:"value"`
+	} `json:"kvs"`
+}
+
+type watchResponse struct {
+	Result struct {
+		Header          responseHeader    `json:"header"`
+		Created         bool              `json:"created"`
+		Canceled        bool              `json:"canceled"`
+		CompactRevision string            `json:"compact_revision"`
+		CancelReason    string            `json:"cancel_reason"`
+		Events          []json.RawMessage `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Config loader designed to load all keys under a prefix from etcd using the v3 HTTP gateway. Keys
+// are split on "/" into nested configuration e.g. "db/host" under the prefix is loaded as "db:host"
+type EtcdLoader struct {
+	prefix string
+	opts   *Options
+	client *http.Client
+
+	mu       sync.Mutex
+	revision int64
+}
+
+// Gets the range of keys starting with the prefix. The end of the range is the prefix with its last
+// byte incremented, and a key and end of "\x00" selects every key
+func (loader *EtcdLoader) keyRange() map[string]any {
+	end := []byte(loader.prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return map[string]any{"key": []byte(loader.prefix), "range_end": end[:i+1]}
+		}
+	}
+
+	return map[string]any{"key": []byte{0}, "range_end": []byte{0}}
+}
+
+// Loads configuration from a source into a map
+func (loader *EtcdLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// Loads configuration from a source into a map, cancelling the request if the context is done
+func (loader *EtcdLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	resp, err := loader.post(ctx, "/v3/kv/range", loader.keyRange())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var rr rangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, fmt.Errorf("etcd prefix %s: %w", loader.prefix, err)
+	}
+
+	revision, _ := strconv.ParseInt(rr.Header.Revision, 10, 64)
+	loader.mu.Lock()
+	loader.revision = revision
+	loader.mu.Unlock()
+
+	if len(rr.Kvs) == 0 {
+		return nil, fmt.Errorf("etcd prefix %s: %w", loader.prefix, cfg.ErrNotFound)
+	}
+
+	pairs := make([]kv.Pair, len(rr.Kvs))
+	for i, p := range rr.Kvs {
+		pairs[i] = kv.Pair{Key: string(p.Key), Value: p.Value}
+	}
+
+	data, err := kv.Nest(pairs, loader.prefix, loader.opts.Decoders)
+	if err != nil {
+		return nil, fmt.Errorf("etcd prefix %s: %w", loader.prefix, err)
+	}
+
+	return data, nil
+}
+
+// Records that changes up to the revision have been reported, so that the next watch starts after
+// them even if the prefix is not loaded again
+func (loader *EtcdLoader) advance(revision string) {
+	r, err := strconv.ParseInt(revision, 10, 64)
+	if err != nil {
+		return
+	}
+
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	if r > loader.revision {
+		loader.revision = r
+	}
+}
+
+// Blocks until any key under the prefix changes after the revision that was last loaded or watched
+func (loader *EtcdLoader) Watch(ctx context.Context) error {
+	loader.mu.Lock()
+	revision := loader.revision
+	loader.mu.Unlock()
+
+	create := loader.keyRange()
+	if revision > 0 {
+		create["start_revision"] = strconv.FormatInt(revision+1, 10)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resp, err := loader.post(ctx, "/v3/watch", map[string]any{"create_request": create})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The gateway streams one JSON object per line until the watch is cancelled
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var wr watchResponse
+		if err := json.Unmarshal(scanner.Bytes(), &wr); err != nil {
+			return fmt.Errorf("etcd prefix %s: %w", loader.prefix, err)
+		} else if wr.Error != nil {
+			return fmt.Errorf("etcd prefix %s: %s", loader.prefix, wr.Error.Message)
+		}
+
+		// Watches starting before the compacted revision are cancelled, in which case changes
+		// may have been missed
+		if wr.Result.Canceled && wr.Result.CompactRevision != "" {
+			loader.advance(wr.Result.Header.Revision)
+			return nil
+		} else if wr.Result.Canceled {
+			return fmt.Errorf("etcd prefix %s: watch cancelled: %s", loader.prefix, wr.Result.CancelReason)
+		}
+
+		if len(wr.Result.Events) > 0 {
+			loader.advance(wr.Result.Header.Revision)
+			return nil
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	} else if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("etcd prefix %s: watch closed", loader.prefix)
+}
+
+func (loader *EtcdLoader) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	token := ""
+	if loader.opts.Username != "" {
+		var err error
+		if token, err = loader.authenticate(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := loader.do(ctx, path, body, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("etcd %s: unexpected status %s", path, resp.Status)
+	}
+
+	return resp, nil
+}
+
+func (loader *EtcdLoader) do(ctx context.Context, path string, body any, token string) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(loader.opts.Address, "/")+path, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	return loader.client.Do(req)
+}
+
+func (loader *EtcdLoader) authenticate(ctx context.Context) (string, error) {
+	resp, err := loader.do(ctx, "/v3/auth/authenticate", map[string]string{
+		"name":     loader.opts.Username,
+		"password": loader.opts.Password,
+	}, "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("etcd authentication failed: %s", resp.Status)
+	}
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", fmt.Errorf("etcd authentication failed: %w", err)
+	}
+
+	return auth.Token, nil
+}
+
+// Reports that keys in etcd are used as they are, as they may contain the delimiter
+func (loader *EtcdLoader) LiteralKeys() bool {
+	return true
+}
+
+var _ cfg.Loader = (*EtcdLoader)(nil)
+var _ cfg.ContextLoader = (*EtcdLoader)(nil)
+var _ cfg.Watcher = (*EtcdLoader)(nil)
+var _ cfg.LiteralKeysLoader = (*EtcdLoader)(nil)
+
+// Creates a new cfg loader designed to load all keys under a prefix from etcd. Uses the standard
+// options if none is provided
+func NewLoader(prefix string, opts *Options) *EtcdLoader {
+	if opts == nil {
+		opts = &StandardOptions
+	}
+	if opts.Address == "" {
+		o := *opts
+		o.Address = StandardOptions.Address
+		opts = &o
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	return &EtcdLoader{
+		prefix: prefix,
+		opts:   opts,
+		client: client,
+	}
+}
@@ -0,0 +1,264 @@
+package cfgetcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/cfgjson"
+)
+
+// Fake of the etcd v3 HTTP gateway, supporting range reads, watches and authentication
+type fakeEtcd struct {
+	mu       sync.Mutex
+	kv       map[string]string
+	revision int64
+	changed  chan struct{}
+	password string
+}
+
+func newFakeEtcd(kv map[string]string) *fakeEtcd {
+	return &fakeEtcd{kv: kv, revision: 1, changed: make(chan struct{})}
+}
+
+func (f *fakeEtcd) put(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.kv[key] = value
+	f.revision++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func inRange(key string, r map[string][]byte) bool {
+	if bytes.Equal(r["key"], []byte{0}) {
+		return true
+	}
+	return key >= string(r["key"]) && key < string(r["range_end"])
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/v3/auth/authenticate" {
+		var auth map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&auth)
+		if auth["password"] != f.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "token"})
+		return
+	} else if f.password != "" && r.Header.Get("Authorization") != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		var req map[string][]byte
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		kvs := make([]map[string][]byte, 0)
+		for k, v := range f.kv {
+			if inRange(k, req) {
+				kvs = append(kvs, map[string][]byte{"key": []byte(k), "value": []byte(v)})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"header": map[string]string{"revision": strconv.FormatInt(f.revision, 10)},
+			"kvs":    kvs,
+		})
+	case "/v3/watch":
+		var req struct {
+			CreateRequest struct {
+				StartRevision string `json:"start_revision"`
+			} `json:"create_request"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		start, _ := strconv.ParseInt(req.CreateRequest.StartRevision, 10, 64)
+
+		fmt.Fprintln(w, `{"result":{"header":{"revision":"1"},"created":true}}`)
+		w.(http.Flusher).Flush()
+
+		for {
+			f.mu.Lock()
+			revision, changed := f.revision, f.changed
+			f.mu.Unlock()
+
+			if start > 0 && revision >= start {
+				fmt.Fprintf(w, `{"result":{"header":{"revision":"%d"},"events":[{"type":"PUT"}]}}`+"\n", revision)
+				return
+			}
+
+			select {
+			case <-changed:
+				if start == 0 {
+					start = revision + 1
+				}
+			case <-r.Context().Done():
+				return
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func Test_EtcdLoader_Load(t *testing.T) {
+	cases := []struct {
+		name     string
+		prefix   string
+		kv       map[string]string
+		opts     Options
+		key      string
+		expected string
+		isErr    bool
+		notFound bool
+	}{
+		{
+			name:     "Nested key",
+			prefix:   "/app/",
+			kv:       map[string]string{"/app/db/host": "localhost", "/apps/db/host": "other"},
+			key:      "db:host",
+			expected: "localhost",
+		},
+		{
+			name:     "Decoded blob",
+			prefix:   "/app/",
+			kv:       map[string]string{"/app/db.json": `{"host": "localhost"}`},
+			opts:     Options{Decoders: map[string]cfg.Decoder{".json": cfgjson.Decode}},
+			key:      "db:host",
+			expected: "localhost",
+		},
+		{
+			name:     "Every key",
+			prefix:   "",
+			kv:       map[string]string{"app/name": "svc"},
+			key:      "app:name",
+			expected: "svc",
+		},
+		{
+			name:     "With authentication",
+			prefix:   "/app/",
+			kv:       map[string]string{"/app/name": "svc"},
+			opts:     Options{Username: "root", Password: "password"},
+			key:      "name",
+			expected: "svc",
+		},
+		{
+			name:   "Authentication failed",
+			prefix: "/app/",
+			kv:     map[string]string{"/app/name": "svc"},
+			opts:   Options{Username: "root", Password: "wrong"},
+			isErr:  true,
+		},
+		{
+			name:     "Prefix not found",
+			prefix:   "/other/",
+			kv:       map[string]string{"/app/name": "svc"},
+			isErr:    true,
+			notFound: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := newFakeEtcd(c.kv)
+			if c.opts.Username != "" {
+				fake.password = "password"
+			}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			c.opts.Address = server.URL
+			config := cfg.New()
+			config.Add(NewLoader(c.prefix, &c.opts))
+
+			err := config.Load()
+			if c.isErr {
+				if err == nil {
+					t.Fatal("No error when error expected")
+				} else if c.notFound != errors.Is(err, cfg.ErrNotFound) {
+					t.Errorf("Not found %v != %v", c.notFound, errors.Is(err, cfg.ErrNotFound))
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if actual := config.MustGetString(c.key); actual != c.expected {
+				t.Errorf("%s != %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_EtcdLoader_Watch(t *testing.T) {
+	fake := newFakeEtcd(map[string]string{"/app/key": "old"})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	config := cfg.New()
+	config.Add(NewLoader("/app/", &Options{Address: server.URL}))
+	if err := config.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reloads := make(chan error, 1)
+	go func() {
+		_ = config.Watch(ctx, func(err error) { reloads <- err })
+	}()
+
+	fake.put("/app/key", "new")
+
+	select {
+	case err := <-reloads:
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for reload")
+	}
+
+	if actual := config.MustGetString("key"); actual != "new" {
+		t.Errorf("new != %s", actual)
+	}
+}
+
+func Test_EtcdLoader_Watch_Twice(t *testing.T) {
+	fake := newFakeEtcd(map[string]string{"/app/key": "old"})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	loader := NewLoader("/app/", &Options{Address: server.URL})
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	fake.put("/app/key", "new")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := loader.Watch(ctx); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// The change was already reported, so watching again without a reload blocks
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := loader.Watch(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("%v != %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,150 @@
+package cfggit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/cfgjson"
+)
+
+// Special options used to control how configuration is read from a git repository
+type Options struct {
+	// Path to the git binary
+	GitPath string
+
+	// Decoders by file extension e.g. ".yaml". Files with an extension that is not listed are decoded
+	// as JSON
+	Decoders map[string]cfg.Decoder
+}
+
+// Standard options that are used if none is provided
+var StandardOptions = Options{
+	GitPath: "git",
+}
+
+// Config loader designed to load a file from a git repository at a specific ref, such as a branch,
+// tag or commit SHA. Files are read from the repository's object database rather than the working
+// tree, so bare repositories are supported. The commit that was loaded is reported as the version
+// of every value in its provenance
+type GitLoader struct {
+	repo string
+	ref  string
+	path string
+	opts *Options
+
+	mu     sync.Mutex
+	commit string
+}
+
+// Runs a git command in the repository, returning its output. Errors include git's own message
+func (loader *GitLoader) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, loader.opts.GitPath, append([]string{"-C", loader.repo}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return out, nil
+}
+
+// Loads configuration from a source into a map
+func (loader *GitLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// Loads configuration from a source into a map, stopping git if the context is done
+func (loader *GitLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	out, err := loader.git(ctx, "rev-parse", "--verify", "--quiet", loader.ref+"^{commit}")
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("ref %s in %s: %w", loader.ref, loader.repo, cfg.ErrNotFound)
+		}
+		return nil, err
+	}
+	commit := strings.TrimSpace(string(out))
+
+	object := fmt.Sprintf("%s:%s", commit, loader.path)
+	if _, err := loader.git(ctx, "cat-file", "-e", object); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%s at %s in %s: %w", loader.path, loader.ref, loader.repo, cfg.ErrNotFound)
+	}
+
+	content, err := loader.git(ctx, "cat-file", "blob", object)
+	if err != nil {
+		return nil, err
+	}
+
+	decode, ok := loader.opts.Decoders[path.Ext(loader.path)]
+	if !ok {
+		decode = cfgjson.Decode
+	}
+
+	data, err := decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s at %s: %w", loader.path, commit, err)
+	}
+
+	loader.mu.Lock()
+	loader.commit = commit
+	loader.mu.Unlock()
+
+	return data, nil
+}
+
+// Gets the SHA of the commit that was last loaded
+func (loader *GitLoader) Version() string {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	return loader.commit
+}
+
+// Reports that keys in the loaded file are used as they are, as they may contain the delimiter
+func (loader *GitLoader) LiteralKeys() bool {
+	return true
+}
+
+var _ cfg.Loader = (*GitLoader)(nil)
+var _ cfg.ContextLoader = (*GitLoader)(nil)
+var _ cfg.VersionedLoader = (*GitLoader)(nil)
+var _ cfg.LiteralKeysLoader = (*GitLoader)(nil)
+
+// Creates a new cfg loader designed to load the file at path, relative to the root of the
+// repository, from the given ref e.g. NewLoader("/srv/config.git", "v1.2.0", "prod/app.json", nil).
+// Uses the standard options if none is provided
+func NewLoader(repo, ref, path string, opts *Options) *GitLoader {
+	if opts == nil {
+		opts = &StandardOptions
+	}
+	if opts.GitPath == "" {
+		o := *opts
+		o.GitPath = StandardOptions.GitPath
+		opts = &o
+	}
+
+	return &GitLoader{
+		repo: repo,
+		ref:  ref,
+		path: strings.TrimPrefix(path, "/"),
+		opts: opts,
+	}
+}
@@ -0,0 +1,146 @@
+package cfggit
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jaredhughes1012/cfg"
+)
+
+// Creates a bare repository with two commits of config.json, tagging the first as v1. Returns the
+// path to the repository and the SHAs of both commits
+func newTestRepo(t *testing.T) (string, []string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	work, bare := filepath.Join(dir, "work"), filepath.Join(dir, "config.git")
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = work
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	if err := os.MkdirAll(filepath.Join(work, "prod"), 0755); err != nil {
+		t.Fatalf("%v", err)
+	}
+	git("init", "-q")
+
+	commits := make([]string, 0)
+	for _, name := range []string{"v1", "v2"} {
+		if err := os.WriteFile(filepath.Join(work, "prod", "config.json"), []byte(`{"name": "`+name+`"}`), 0644); err != nil {
+			t.Fatalf("%v", err)
+		}
+		git("add", "-A")
+		git("commit", "-q", "-m", name)
+		commits = append(commits, git("rev-parse", "HEAD"))
+	}
+	git("tag", "v1", commits[0])
+	git("clone", "-q", "--bare", work, bare)
+
+	// Changes to the working tree are never loaded
+	if err := os.WriteFile(filepath.Join(work, "prod", "config.json"), []byte(`{"name": "dirty"}`), 0644); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	return bare, commits
+}
+
+func Test_GitLoader_Load(t *testing.T) {
+	repo, commits := newTestRepo(t)
+
+	cases := []struct {
+		name     string
+		repo     string
+		ref      string
+		path     string
+		expected string
+		commit   string
+		isErr    bool
+		notFound bool
+	}{
+		{
+			name:     "Branch",
+			repo:     repo,
+			ref:      "HEAD",
+			path:     "prod/config.json",
+			expected: "v2",
+			commit:   commits[1],
+		},
+		{
+			name:     "Tag",
+			repo:     repo,
+			ref:      "v1",
+			path:     "/prod/config.json",
+			expected: "v1",
+			commit:   commits[0],
+		},
+		{
+			name:     "Commit SHA",
+			repo:     repo,
+			ref:      commits[0],
+			path:     "prod/config.json",
+			expected: "v1",
+			commit:   commits[0],
+		},
+		{
+			name:     "Ref not found",
+			repo:     repo,
+			ref:      "missing",
+			path:     "prod/config.json",
+			isErr:    true,
+			notFound: true,
+		},
+		{
+			name:     "Path not found",
+			repo:     repo,
+			ref:      "HEAD",
+			path:     "dev/config.json",
+			isErr:    true,
+			notFound: true,
+		},
+		{
+			name:  "Repository not found",
+			repo:  filepath.Join(t.TempDir(), "missing"),
+			ref:   "HEAD",
+			path:  "prod/config.json",
+			isErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := cfg.New()
+			config.AddNamed("git", NewLoader(c.repo, c.ref, c.path, nil), cfg.PriorityNormal)
+
+			err := config.Load()
+			if c.isErr {
+				if err == nil {
+					t.Fatal("No error when error expected")
+				} else if c.notFound != errors.Is(err, cfg.ErrNotFound) {
+					t.Errorf("Not found %v != %v: %v", c.notFound, errors.Is(err, cfg.ErrNotFound), err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if actual := config.MustGetString("name"); actual != c.expected {
+				t.Errorf("%s != %s", c.expected, actual)
+			}
+			if p, _ := config.Provenance("name"); p.Version != c.commit {
+				t.Errorf("%s != %s", c.commit, p.Version)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package cfghttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/cfgjson"
+)
+
+// Special options used to control how configuration is fetched over HTTP
+type Options struct {
+	// Headers added to every request, such as authorization headers
+	Headers http.Header
+
+	// If set, sent as a bearer token in the Authorization header
+	BearerToken string
+
+	// If set, used for requests instead of a client created from TLSConfig and Timeout
+	Client *http.Client
+
+	// TLS settings such as client certificates or custom root CAs
+	TLSConfig *tls.Config
+
+	// Timeout for each request
+	Timeout time.Duration
+
+	// Maximum size of a response body in bytes. Larger responses fail to load
+	MaxBytes int64
+
+	// Decoders by media type e.g. "application/yaml". Responses with a media type that is not listed
+	// are decoded as JSON
+	Decoders map[string]cfg.Decoder
+}
+
+// Standard options that are used if none is provided
+var StandardOptions = Options{
+	Timeout:  30 * time.Second,
+	MaxBytes: 10 << 20,
+}
+
+// Config loader designed to load a document from an HTTP(S) URL. Responses are cached using their
+// ETag, so unchanged documents are not downloaded or decoded again
+type HttpLoader struct {
+	url    string
+	opts   *Options
+	client *http.Client
+
+	mu   sync.Mutex
+	etag string
+	data map[string]any
+}
+
+// Loads configuration from a source into a map
+func (loader *HttpLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// Loads configuration from a source into a map, cancelling the request if the context is done
+func (loader *HttpLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, loader.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, vals := range loader.opts.Headers {
+		for _, v := range vals {
+			req.Header.Add(k, v)
+		}
+	}
+	if loader.opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+loader.opts.BearerToken)
+	}
+
+	loader.mu.Lock()
+	etag, cached := loader.etag, loader.data
+	loader.mu.Unlock()
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := loader.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", loader.url, cfg.ErrNotFound)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("%s: unexpected status %s", loader.url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, loader.opts.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	} else if int64(len(body)) > loader.opts.MaxBytes {
+		return nil, fmt.Errorf("%s: response is larger than %d bytes", loader.url, loader.opts.MaxBytes)
+	}
+
+	data, err := loader.decoder(resp.Header.Get("Content-Type"))(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", loader.url, err)
+	}
+
+	loader.mu.Lock()
+	loader.etag, loader.data = resp.Header.Get("ETag"), data
+	loader.mu.Unlock()
+
+	return data, nil
+}
+
+func (loader *HttpLoader) decoder(contentType string) cfg.Decoder {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		if d, ok := loader.opts.Decoders[mediaType]; ok {
+			return d
+		}
+	}
+
+	return cfgjson.Decode
+}
+
+// Reports that keys in fetched documents are used as they are, as they may contain the delimiter
+func (loader *HttpLoader) LiteralKeys() bool {
+	return true
+}
+
+var _ cfg.Loader = (*HttpLoader)(nil)
+var _ cfg.ContextLoader = (*HttpLoader)(nil)
+var _ cfg.LiteralKeysLoader = (*HttpLoader)(nil)
+
+// Creates a new cfg loader designed to load a document from an HTTP(S) URL. Uses the standard options
+// if none is provided
+func NewLoader(url string, opts *Options) *HttpLoader {
+	if opts == nil {
+		opts = &StandardOptions
+	}
+	if opts.MaxBytes <= 0 {
+		o := *opts
+		o.MaxBytes = StandardOptions.MaxBytes
+		opts = &o
+	}
+
+	client := opts.Client
+	if client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = opts.TLSConfig
+		client = &http.Client{Transport: transport, Timeout: opts.Timeout}
+	}
+
+	return &HttpLoader{
+		url:    url,
+		opts:   opts,
+		client: client,
+	}
+}
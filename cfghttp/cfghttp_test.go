@@ -0,0 +1,166 @@
+package cfghttp
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jaredhughes1012/cfg"
+)
+
+func decodeKeyValues(r io.Reader) (map[string]any, error) {
+	data := make(map[string]any)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		k, v, _ := strings.Cut(scanner.Text(), "=")
+		data[k] = v
+	}
+
+	return data, scanner.Err()
+}
+
+func Test_HttpLoader_Load(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		opts        Options
+		expected    string
+		isErr       bool
+		notFound    bool
+	}{
+		{
+			name:        "JSON",
+			contentType: "application/json",
+			body:        `{"key": "value"}`,
+			status:      http.StatusOK,
+			expected:    "value",
+		},
+		{
+			name:        "Custom decoder",
+			contentType: "text/plain; charset=utf-8",
+			body:        "key=value",
+			status:      http.StatusOK,
+			opts:        Options{Decoders: map[string]cfg.Decoder{"text/plain": decodeKeyValues}},
+			expected:    "value",
+		},
+		{
+			name:     "Not found",
+			status:   http.StatusNotFound,
+			isErr:    true,
+			notFound: true,
+		},
+		{
+			name:   "Server error",
+			status: http.StatusInternalServerError,
+			isErr:  true,
+		},
+		{
+			name:        "Invalid body",
+			contentType: "application/json",
+			body:        `{"key"`,
+			status:      http.StatusOK,
+			isErr:       true,
+		},
+		{
+			name:        "Too large",
+			contentType: "application/json",
+			body:        `{"key": "value"}`,
+			status:      http.StatusOK,
+			opts:        Options{MaxBytes: 4},
+			isErr:       true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", c.contentType)
+				w.WriteHeader(c.status)
+				_, _ = w.Write([]byte(c.body))
+			}))
+			defer server.Close()
+
+			data, err := NewLoader(server.URL, &c.opts).Load()
+			if c.isErr {
+				if err == nil {
+					t.Error("No error when error expected")
+				} else if c.notFound != errors.Is(err, cfg.ErrNotFound) {
+					t.Errorf("Unexpected not found error %v", err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if c.expected != data["key"] {
+				t.Errorf("Value %s != %v", c.expected, data["key"])
+			}
+		})
+	}
+}
+
+func Test_HttpLoader_ETag(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get("X-Service") != "svc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"key": "value"}`))
+	}))
+	defer server.Close()
+
+	loader := NewLoader(server.URL, &Options{
+		BearerToken: "token",
+		Headers:     http.Header{"X-Service": []string{"svc"}},
+	})
+
+	for i := 0; i < 2; i++ {
+		data, err := loader.Load()
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		if data["key"] != "value" {
+			t.Errorf("Value value != %v", data["key"])
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Requests 2 != %d", requests)
+	}
+}
+
+func Test_HttpLoader_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"key": "value"}`))
+	}))
+	defer server.Close()
+
+	if _, err := NewLoader(server.URL, nil).Load(); err == nil {
+		t.Error("No error for untrusted certificate")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	data, err := NewLoader(server.URL, &Options{TLSConfig: &tls.Config{RootCAs: pool}}).Load()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if data["key"] != "value" {
+		t.Errorf("Value value != %v", data["key"])
+	}
+}
@@ -2,6 +2,7 @@ package cfgjson
 
 import (
 	"encoding/json"
+	"io"
 	"os"
 
 	"github.com/jaredhughes1012/cfg"
@@ -13,6 +14,16 @@ type JsonLoader struct {
 	required bool
 }
 
+// Decodes a JSON object into a map. Can be used by other loaders that read JSON documents
+func Decode(r io.Reader) (map[string]any, error) {
+	var data map[string]any
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
 // Loads configuration from a source into a map
 func (loader JsonLoader) Load() (map[string]any, error) {
 	f, err := os.Open(loader.path)
@@ -23,16 +34,19 @@ func (loader JsonLoader) Load() (map[string]any, error) {
 			return nil, err
 		}
 	}
+	defer f.Close()
 
-	var data map[string]any
-	if err := json.NewDecoder(f).Decode(&data); err != nil {
-		return nil, err
-	}
+	return Decode(f)
+}
 
-	return data, nil
+// Reports that keys in JSON documents are used as they are, as they may contain the delimiter
+func (loader JsonLoader) LiteralKeys() bool {
+	return true
 }
 
 var _ cfg.Loader = (*JsonLoader)(nil)
+var _ cfg.LiteralKeysLoader = (*JsonLoader)(nil)
+var _ cfg.Decoder = Decode
 
 func NewLoader(path string, required bool) *JsonLoader {
 	return &JsonLoader{
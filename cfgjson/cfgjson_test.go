@@ -0,0 +1,50 @@
+package cfgjson
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaredhughes1012/cfg"
+)
+
+func Test_JsonLoader_Load(t *testing.T) {
+	cases := []struct {
+		name     string
+		delim    string
+		segs     []string
+		expected string
+	}{
+		{name: "URL key", delim: ":", segs: []string{"hosts", "http://a"}, expected: "a"},
+		{name: "IPv6 key", delim: ":", segs: []string{"hosts", "[::1]"}, expected: "localhost"},
+		{name: "Dotted key", delim: ".", segs: []string{"hosts", "example.com"}, expected: "example"},
+		{name: "Nested", delim: ".", segs: []string{"db", "port"}, expected: "5432"},
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	doc := `{
+		"hosts": {"http://a": "a", "[::1]": "localhost", "example.com": "example"},
+		"db": {"port": "5432"}
+	}`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := cfg.New()
+			config.SetDelimiter(c.delim)
+			config.Add(NewLoader(path, true))
+			if err := config.Load(); err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			actual, err := config.GetString(config.Key(c.segs...))
+			if err != nil {
+				t.Fatalf("%v", err)
+			} else if actual != c.expected {
+				t.Errorf("%s != %s", c.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package cfgsql
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/internal/mapconvert"
+)
+
+// Special options used to control how configuration is read from a database
+type Options struct {
+	// Separator used to split keys into nested configuration e.g. "db.host" is loaded as "db:host"
+	Separator string
+
+	// If set, the query is run at this interval while the configuration is watched, and the
+	// configuration is reloaded whenever the results change
+	PollInterval time.Duration
+}
+
+// Standard options that are used if none is provided
+var StandardOptions = Options{
+	Separator: ".",
+}
+
+const (
+	// Query used to load settings from a settings(key, value) table. KEY is a reserved word in
+	// MySQL, so use MySQLSettingsQuery there instead
+	SettingsQuery = "SELECT key, value FROM settings"
+
+	// Query used to load settings from a settings(key, value) table in MySQL, which requires the
+	// reserved word KEY to be quoted with backticks
+	MySQLSettingsQuery = "SELECT `key`, value FROM settings"
+)
+
+// Config loader designed to load settings from a database table. The query must return two
+// columns, the key and the value of each setting, in any order. Rows with a NULL value are skipped
+type SqlLoader struct {
+	db    *sql.DB
+	query string
+	opts  *Options
+
+	mu          sync.Mutex
+	fingerprint [sha256.Size]byte
+}
+
+// Runs the query and gets the settings it returns, along with a fingerprint of the results that
+// changes whenever any setting does
+func (loader *SqlLoader) settings(ctx context.Context) (map[string]any, [sha256.Size]byte, error) {
+	var fingerprint [sha256.Size]byte
+
+	rows, err := loader.db.QueryContext(ctx, loader.query)
+	if err != nil {
+		return nil, fingerprint, err
+	}
+	defer rows.Close()
+
+	rowsByKey := make([][2]string, 0)
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fingerprint, err
+		} else if value.Valid {
+			rowsByKey = append(rowsByKey, [2]string{key, value.String})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fingerprint, err
+	}
+
+	// Rows are sorted so that the fingerprint does not change when the database returns the same
+	// rows in a different order
+	sort.Slice(rowsByKey, func(i, j int) bool {
+		if rowsByKey[i][0] != rowsByKey[j][0] {
+			return rowsByKey[i][0] < rowsByKey[j][0]
+		}
+		return rowsByKey[i][1] < rowsByKey[j][1]
+	})
+
+	data := make(map[string]any)
+	hash := sha256.New()
+	for _, row := range rowsByKey {
+		key, value := row[0], row[1]
+
+		// Length prefixes keep distinct rows from producing the same fingerprint
+		fmt.Fprintf(hash, "%d:%s%d:%s", len(key), key, len(value), value)
+
+		entry := make(map[string]any)
+		mapconvert.SetPath(entry, strings.Split(key, loader.opts.Separator), value)
+		data = mapconvert.Fold(entry, data)
+	}
+
+	copy(fingerprint[:], hash.Sum(nil))
+	return data, fingerprint, nil
+}
+
+// Loads configuration from a source into a map
+func (loader *SqlLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// Loads configuration from a source into a map, cancelling the query if the context is done
+func (loader *SqlLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	data, fingerprint, err := loader.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	loader.mu.Lock()
+	loader.fingerprint = fingerprint
+	loader.mu.Unlock()
+
+	return data, nil
+}
+
+// Polls the query until its results differ from those last loaded. Blocks until the context is
+// done if polling is not enabled
+func (loader *SqlLoader) Watch(ctx context.Context) error {
+	if loader.opts.PollInterval <= 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ticker := time.NewTicker(loader.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		_, fingerprint, err := loader.settings(ctx)
+		if err != nil {
+			return err
+		}
+
+		loader.mu.Lock()
+		changed := fingerprint != loader.fingerprint
+		loader.mu.Unlock()
+
+		if changed {
+			return nil
+		}
+	}
+}
+
+// Reports that setting keys are used as they are, as they may contain the delimiter
+func (loader *SqlLoader) LiteralKeys() bool {
+	return true
+}
+
+var _ cfg.Loader = (*SqlLoader)(nil)
+var _ cfg.ContextLoader = (*SqlLoader)(nil)
+var _ cfg.Watcher = (*SqlLoader)(nil)
+var _ cfg.LiteralKeysLoader = (*SqlLoader)(nil)
+
+// Creates a new cfg loader designed to load settings from a database using the given query, such as
+// SettingsQuery. Uses the standard options if none is provided
+func NewLoader(db *sql.DB, query string, opts *Options) *SqlLoader {
+	if opts == nil {
+		opts = &StandardOptions
+	}
+	if opts.Separator == "" {
+		o := *opts
+		o.Separator = StandardOptions.Separator
+		opts = &o
+	}
+
+	return &SqlLoader{
+		db:    db,
+		query: query,
+		opts:  opts,
+	}
+}
@@ -0,0 +1,240 @@
+package cfgsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jaredhughes1012/cfg"
+)
+
+// In-memory database driver with a single settings table. Every query returns the whole table
+// ordered by key, or in reverse if set, and queries other than SettingsQuery fail
+type fakeDriver struct {
+	mu       sync.Mutex
+	settings map[string]any
+	reverse  bool
+}
+
+var testDriver = &fakeDriver{}
+
+func init() {
+	sql.Register("cfgsqltest", testDriver)
+}
+
+func (d *fakeDriver) set(settings map[string]any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.settings = settings
+	d.reverse = false
+}
+
+func (d *fakeDriver) setReverse(reverse bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reverse = reverse
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if query != SettingsQuery {
+		return nil, errors.New("no such table")
+	}
+	return &fakeStmt{driver: c.driver}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeStmt struct {
+	driver *fakeDriver
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return 0
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec is not supported")
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+
+	rows := &fakeRows{}
+	for k, v := range s.driver.settings {
+		rows.rows = append(rows.rows, []driver.Value{k, v})
+	}
+	sort.Slice(rows.rows, func(i, j int) bool {
+		return (rows.rows[i][0].(string) < rows.rows[j][0].(string)) != s.driver.reverse
+	})
+
+	return rows, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"key", "value"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func openTestDB(t *testing.T, settings map[string]any) *sql.DB {
+	testDriver.set(settings)
+	db, err := sql.Open("cfgsqltest", "")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func Test_SqlLoader_Load(t *testing.T) {
+	cases := []struct {
+		name     string
+		query    string
+		settings map[string]any
+		opts     *Options
+		expected map[string]string
+		missing  string
+		isErr    bool
+	}{
+		{
+			name:     "Nested keys",
+			query:    SettingsQuery,
+			settings: map[string]any{"db.host": "localhost", "db.port": int64(5432), "name": []byte("svc")},
+			expected: map[string]string{"db:host": "localhost", "db:port": "5432", "name": "svc"},
+		},
+		{
+			name:     "Custom separator",
+			query:    SettingsQuery,
+			settings: map[string]any{"db/host": "localhost"},
+			opts:     &Options{Separator: "/"},
+			expected: map[string]string{"db:host": "localhost"},
+		},
+		{
+			name:     "Null value",
+			query:    SettingsQuery,
+			settings: map[string]any{"db.host": nil, "name": "svc"},
+			expected: map[string]string{"name": "svc"},
+			missing:  "db:host",
+		},
+		{
+			name:  "Invalid query",
+			query: "SELECT key, value FROM missing",
+			isErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := cfg.New()
+			config.Add(NewLoader(openTestDB(t, c.settings), c.query, c.opts))
+
+			err := config.Load()
+			if c.isErr {
+				if err == nil {
+					t.Error("No error when error expected")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			for k, expected := range c.expected {
+				if actual := config.MustGetString(k); actual != expected {
+					t.Errorf("%s %s != %s", k, expected, actual)
+				}
+			}
+			if _, err := config.GetString(c.missing); c.missing != "" && err == nil {
+				t.Errorf("%s found", c.missing)
+			}
+		})
+	}
+}
+
+func Test_SqlLoader_Watch(t *testing.T) {
+	config := cfg.New()
+	config.Add(NewLoader(openTestDB(t, map[string]any{"name": "old"}), SettingsQuery, &Options{PollInterval: 10 * time.Millisecond}))
+	if err := config.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reloads := make(chan error, 1)
+	go func() {
+		_ = config.Watch(ctx, func(err error) { reloads <- err })
+	}()
+
+	testDriver.set(map[string]any{"name": "new"})
+
+	select {
+	case err := <-reloads:
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for reload")
+	}
+
+	if actual := config.MustGetString("name"); actual != "new" {
+		t.Errorf("new != %s", actual)
+	}
+}
+
+func Test_SqlLoader_Watch_Reordered(t *testing.T) {
+	loader := NewLoader(openTestDB(t, map[string]any{"a": "1", "b": "2"}), SettingsQuery, &Options{PollInterval: 10 * time.Millisecond})
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	testDriver.setReverse(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := loader.Watch(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("%v != %v", context.DeadlineExceeded, err)
+	}
+}
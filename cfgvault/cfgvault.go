@@ -0,0 +1,457 @@
+package cfgvault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/internal/mapconvert"
+)
+
+// Path of the service account token mounted into Kubernetes pods
+const kubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// Method used to log in to Vault
+type Auth struct {
+	// Static token, if not logging in through an auth method
+	token string
+
+	// Mount of the auth method and a function that builds its login request
+	mount string
+	login func() (map[string]string, error)
+}
+
+// Authenticates with a static token, such as a service token or the value of VAULT_TOKEN
+func TokenAuth(token string) Auth {
+	return Auth{token: token}
+}
+
+// Authenticates with the AppRole auth method mounted at the given path, "approle" by default
+func AppRoleAuth(mount, roleID, secretID string) Auth {
+	if mount == "" {
+		mount = "approle"
+	}
+
+	return Auth{
+		mount: mount,
+		login: func() (map[string]string, error) {
+			return map[string]string{"role_id": roleID, "secret_id": secretID}, nil
+		},
+	}
+}
+
+// Authenticates with the Kubernetes auth method mounted at the given path, "kubernetes" by default.
+// The service account token is read from jwtPath on every login, or from the standard location in
+// the pod if jwtPath is empty
+func KubernetesAuth(mount, role, jwtPath string) Auth {
+	if mount == "" {
+		mount = "kubernetes"
+	}
+	if jwtPath == "" {
+		jwtPath = kubernetesTokenPath
+	}
+
+	return Auth{
+		mount: mount,
+		login: func() (map[string]string, error) {
+			jwt, err := os.ReadFile(jwtPath)
+			if err != nil {
+				return nil, err
+			}
+
+			return map[string]string{"role": role, "jwt": strings.TrimSpace(string(jwt))}, nil
+		},
+	}
+}
+
+// Special options used to control how secrets are read from Vault
+type Options struct {
+	// Address of the Vault server
+	Address string
+
+	// If set, sent as the namespace of every request
+	Namespace string
+
+	// Method used to log in. Uses the token in the VAULT_TOKEN environment variable if not set
+	Auth *Auth
+
+	// If set, used for requests instead of a client created with Timeout
+	Client *http.Client
+
+	// Timeout for each request
+	Timeout time.Duration
+
+	// Version of the KV secrets engine. Version 2 reads the latest version of the secret. Use
+	// version 1 for KV v1 mounts and other secrets engines, such as dynamic database credentials
+	KVVersion int
+
+	// Key path that the values of the secret are loaded under e.g. ["db"] loads the secret's
+	// "password" value as "db:password"
+	KeyPrefix []string
+}
+
+// Standard options that are used if none is provided
+var StandardOptions = Options{
+	Address:   "http://127.0.0.1:8200",
+	Timeout:   30 * time.Second,
+	KVVersion: 2,
+}
+
+// Response body of the Vault API
+type response struct {
+	LeaseID       string         `json:"lease_id"`
+	LeaseDuration int            `json:"lease_duration"`
+	Renewable     bool           `json:"renewable"`
+	Data          map[string]any `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// Lease of a dynamic secret
+type lease struct {
+	id        string
+	duration  time.Duration
+	renewable bool
+
+	// Time the lease was obtained or last renewed
+	renewed time.Time
+
+	// Set once Watch has reported that the lease must be replaced, so that it is only reported once
+	signalled bool
+}
+
+// Gets the time at which the lease should be renewed, two thirds of the way through it
+func (l lease) renewAt() time.Time {
+	return l.renewed.Add(l.duration * 2 / 3)
+}
+
+// Config loader designed to load a secret from Vault. All values loaded are sensitive. Leases of
+// dynamic secrets are renewed while the configuration is watched, and the secret is loaded again
+// once its lease can no longer be renewed
+type VaultLoader struct {
+	mount string
+	path  string
+	opts  *Options
+
+	client *http.Client
+
+	mu           sync.Mutex
+	token        string
+	tokenExpires time.Time
+	lease        lease
+}
+
+// Loads configuration from a source into a map
+func (loader *VaultLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+// Loads configuration from a source into a map, cancelling the request if the context is done
+func (loader *VaultLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	path := fmt.Sprintf("%s/%s", loader.mount, loader.path)
+	if loader.opts.KVVersion == 2 {
+		path = fmt.Sprintf("%s/data/%s", loader.mount, loader.path)
+	}
+
+	resp, err := loader.request(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data := resp.Data
+	if loader.opts.KVVersion == 2 {
+		// KV v2 nests the secret inside its version metadata
+		data, _ = resp.Data["data"].(map[string]any)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("vault secret %s: %w", path, cfg.ErrNotFound)
+	}
+
+	loader.mu.Lock()
+	loader.lease = lease{
+		id:        resp.LeaseID,
+		duration:  time.Duration(resp.LeaseDuration) * time.Second,
+		renewable: resp.Renewable,
+		renewed:   time.Now(),
+	}
+	loader.mu.Unlock()
+
+	if len(loader.opts.KeyPrefix) == 0 {
+		return data, nil
+	}
+
+	nested := make(map[string]any)
+	mapconvert.SetPath(nested, loader.opts.KeyPrefix, data)
+	return nested, nil
+}
+
+// Renews the lease of a dynamic secret until it can no longer be renewed, then returns so that the
+// secret is loaded again before it expires. Blocks until the context is done for secrets without a
+// lease
+func (loader *VaultLoader) Watch(ctx context.Context) error {
+	for {
+		loader.mu.Lock()
+		current := loader.lease
+		loader.mu.Unlock()
+
+		// Secrets without a lease have nothing to renew, and a lease already reported is not renewed
+		// again until it is replaced by loading the secret
+		if current.id == "" || current.signalled {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		timer := time.NewTimer(time.Until(current.renewAt()))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+
+		if !current.renewable {
+			loader.signal(current)
+			return nil
+		}
+
+		resp, err := loader.request(ctx, http.MethodPut, "sys/leases/renew", map[string]any{
+			"lease_id":  current.id,
+			"increment": int(current.duration.Seconds()),
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		} else if err != nil {
+			// The secret will expire, so a new one is loaded instead
+			loader.signal(current)
+			return nil
+		}
+
+		renewed := time.Duration(resp.LeaseDuration) * time.Second
+		loader.mu.Lock()
+		loader.lease = lease{
+			id:       current.id,
+			duration: renewed,
+			renewed:  time.Now(),
+
+			// A shorter lease than requested has reached its maximum TTL, so the secret must be
+			// replaced before it expires
+			renewable: resp.Renewable && renewed >= current.duration,
+		}
+		loader.mu.Unlock()
+	}
+}
+
+// Records that Watch reported the lease must be replaced, unless it was already replaced
+func (loader *VaultLoader) signal(l lease) {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	if loader.lease.id == l.id {
+		loader.lease.signalled = true
+	}
+}
+
+// Reports that all values loaded from Vault are sensitive
+func (loader *VaultLoader) Sensitive() bool {
+	return true
+}
+
+// Sends a request to the Vault API, logging in first if needed. Logs in again and retries once if
+// the token is rejected
+func (loader *VaultLoader) request(ctx context.Context, method, path string, body any) (*response, error) {
+	token, err := loader.login(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, status, err := loader.do(ctx, method, path, token, body)
+	if status == http.StatusForbidden && loader.opts.Auth.login != nil {
+		if token, err = loader.login(ctx, true); err != nil {
+			return nil, err
+		}
+		resp, status, err = loader.do(ctx, method, path, token, body)
+	}
+
+	switch {
+	case err != nil:
+		return nil, err
+	case status == http.StatusNotFound:
+		return nil, fmt.Errorf("vault %s: %w", path, cfg.ErrNotFound)
+	case status < 200 || status > 299:
+		return nil, fmt.Errorf("vault %s: unexpected status %d: %s", path, status, strings.Join(resp.Errors, "; "))
+	}
+
+	return resp, nil
+}
+
+// Gets a token, logging in if there is no valid token or if forced to
+func (loader *VaultLoader) login(ctx context.Context, force bool) (string, error) {
+	auth := loader.opts.Auth
+	if auth.login == nil {
+		return auth.token, nil
+	}
+
+	loader.mu.Lock()
+	token, expires := loader.token, loader.tokenExpires
+	loader.mu.Unlock()
+	if !force && token != "" && (expires.IsZero() || time.Now().Before(expires)) {
+		return token, nil
+	}
+
+	params, err := auth.login()
+	if err != nil {
+		return "", fmt.Errorf("vault login: %w", err)
+	}
+
+	resp, status, err := loader.do(ctx, http.MethodPost, fmt.Sprintf("auth/%s/login", auth.mount), "", params)
+	if err != nil {
+		return "", fmt.Errorf("vault login: %w", err)
+	} else if status != http.StatusOK || resp.Auth == nil {
+		return "", fmt.Errorf("vault login: unexpected status %d: %s", status, strings.Join(resp.Errors, "; "))
+	}
+
+	token, expires = resp.Auth.ClientToken, time.Time{}
+	if resp.Auth.LeaseDuration > 0 {
+		expires = time.Now().Add(time.Duration(resp.Auth.LeaseDuration) * time.Second)
+	}
+
+	loader.mu.Lock()
+	loader.token, loader.tokenExpires = token, expires
+	loader.mu.Unlock()
+
+	return token, nil
+}
+
+func (loader *VaultLoader) do(ctx context.Context, method, path, token string, body any) (*response, int, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, 0, err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	url := fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(loader.opts.Address, "/"), strings.Trim(path, "/"))
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if loader.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", loader.opts.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpResp, err := loader.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer httpResp.Body.Close()
+
+	var resp response
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil && err != io.EOF {
+		return nil, httpResp.StatusCode, fmt.Errorf("vault %s: %w", path, err)
+	}
+
+	return &resp, httpResp.StatusCode, nil
+}
+
+// Reports that keys of Vault secrets are used as they are, as they may contain the delimiter
+func (loader *VaultLoader) LiteralKeys() bool {
+	return true
+}
+
+var _ cfg.Loader = (*VaultLoader)(nil)
+var _ cfg.ContextLoader = (*VaultLoader)(nil)
+var _ cfg.Watcher = (*VaultLoader)(nil)
+var _ cfg.SensitiveLoader = (*VaultLoader)(nil)
+var _ cfg.LiteralKeysLoader = (*VaultLoader)(nil)
+
+// Creates a new cfg loader designed to load the secret at a path of the secrets engine mounted at
+// mount e.g. NewLoader("secret", "myapp/db", nil). Uses the standard options if none is provided
+func NewLoader(mount, path string, opts *Options) *VaultLoader {
+	if opts == nil {
+		opts = &StandardOptions
+	}
+
+	o := *opts
+	if o.Address == "" {
+		o.Address = StandardOptions.Address
+	}
+	if o.KVVersion == 0 {
+		o.KVVersion = StandardOptions.KVVersion
+	}
+	if o.Auth == nil {
+		auth := TokenAuth(os.Getenv("VAULT_TOKEN"))
+		o.Auth = &auth
+	}
+
+	client := o.Client
+	if client == nil {
+		client = &http.Client{Timeout: o.Timeout}
+	}
+
+	return &VaultLoader{
+		mount:  strings.Trim(mount, "/"),
+		path:   strings.Trim(path, "/"),
+		opts:   &o,
+		client: client,
+	}
+}
+
+// Creates a resolver for "vault://mount/path#field" references, which resolve to a field of the
+// secret at path in the secrets engine mounted at mount e.g. "vault://secret/db#password". References
+// without a field resolve to the whole secret. Register it with cfg.RegisterResolver("vault", ...).
+// Uses the standard options if none is provided, and the key prefix of the options is ignored
+func NewResolver(opts *Options) cfg.Resolver {
+	if opts == nil {
+		opts = &StandardOptions
+	}
+	o := *opts
+	o.KeyPrefix = nil
+
+	var mu sync.Mutex
+	loaders := make(map[string]*VaultLoader)
+
+	return func(ctx context.Context, ref *url.URL) (any, error) {
+		// Loaders are kept so that their tokens are reused
+		mu.Lock()
+		loader, ok := loaders[ref.Host+ref.Path]
+		if !ok {
+			loader = NewLoader(ref.Host, ref.Path, &o)
+			loaders[ref.Host+ref.Path] = loader
+		}
+		mu.Unlock()
+
+		data, err := loader.LoadContext(ctx)
+		if err != nil {
+			return nil, err
+		} else if ref.Fragment == "" {
+			return data, nil
+		}
+
+		v, ok := data[ref.Fragment]
+		if !ok {
+			return nil, fmt.Errorf("vault secret %s%s has no field %s: %w", ref.Host, ref.Path, ref.Fragment, cfg.ErrNotFound)
+		}
+
+		return v, nil
+	}
+}
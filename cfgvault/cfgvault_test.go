@@ -0,0 +1,311 @@
+package cfgvault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jaredhughes1012/cfg"
+)
+
+// Stub of the Vault HTTP API with a KV v1 mount at "kv", a KV v2 mount at "secret", a dynamic
+// secrets engine at "database" and the AppRole and Kubernetes auth methods
+type fakeVault struct {
+	mu      sync.Mutex
+	issued  int
+	renewed int
+
+	// Lease duration returned when renewing, in seconds
+	renewDuration int
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	switch r.URL.Path {
+	case "/v1/auth/approle/login":
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			writeJson(w, http.StatusBadRequest, map[string]any{"errors": []string{"invalid role or secret ID"}})
+			return
+		}
+		writeJson(w, http.StatusOK, map[string]any{"auth": map[string]any{"client_token": "approle-token", "lease_duration": 3600}})
+		return
+	case "/v1/auth/kubernetes/login":
+		if body["role"] != "app" || body["jwt"] != "jwt" {
+			writeJson(w, http.StatusBadRequest, map[string]any{"errors": []string{"invalid role or jwt"}})
+			return
+		}
+		writeJson(w, http.StatusOK, map[string]any{"auth": map[string]any{"client_token": "kubernetes-token", "lease_duration": 3600}})
+		return
+	}
+
+	switch r.Header.Get("X-Vault-Token") {
+	case "root", "approle-token", "kubernetes-token":
+	default:
+		writeJson(w, http.StatusForbidden, map[string]any{"errors": []string{"permission denied"}})
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/v1/kv/app":
+		writeJson(w, http.StatusOK, map[string]any{"data": map[string]any{"password": "v1"}})
+	case "/v1/secret/data/app":
+		writeJson(w, http.StatusOK, map[string]any{"data": map[string]any{
+			"data":     map[string]any{"password": "v2"},
+			"metadata": map[string]any{"version": 3},
+		}})
+	case "/v1/database/creds/app":
+		f.issued++
+		writeJson(w, http.StatusOK, map[string]any{
+			"lease_id":       "database/creds/app/1",
+			"lease_duration": 1,
+			"renewable":      true,
+			"data":           map[string]any{"username": "user" + strconv.Itoa(f.issued)},
+		})
+	case "/v1/sys/leases/renew":
+		f.renewed++
+		writeJson(w, http.StatusOK, map[string]any{
+			"lease_id":       body["lease_id"],
+			"lease_duration": f.renewDuration,
+			"renewable":      true,
+		})
+	default:
+		writeJson(w, http.StatusNotFound, map[string]any{"errors": []string{}})
+	}
+}
+
+func Test_VaultLoader_Load(t *testing.T) {
+	jwtPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(jwtPath, []byte("jwt\n"), 0600); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	token := TokenAuth("root")
+	badToken := TokenAuth("bad")
+	appRole := AppRoleAuth("", "role", "secret")
+	badAppRole := AppRoleAuth("", "role", "wrong")
+	kubernetes := KubernetesAuth("", "app", jwtPath)
+
+	cases := []struct {
+		name     string
+		mount    string
+		path     string
+		opts     Options
+		key      string
+		expected string
+		isErr    bool
+		notFound bool
+	}{
+		{
+			name:     "KV v2",
+			mount:    "secret",
+			path:     "app",
+			opts:     Options{Auth: &token},
+			key:      "password",
+			expected: "v2",
+		},
+		{
+			name:     "KV v1",
+			mount:    "kv",
+			path:     "app",
+			opts:     Options{Auth: &token, KVVersion: 1},
+			key:      "password",
+			expected: "v1",
+		},
+		{
+			name:     "Key prefix",
+			mount:    "secret",
+			path:     "app",
+			opts:     Options{Auth: &token, KeyPrefix: []string{"db"}},
+			key:      "db:password",
+			expected: "v2",
+		},
+		{
+			name:     "AppRole",
+			mount:    "secret",
+			path:     "app",
+			opts:     Options{Auth: &appRole},
+			key:      "password",
+			expected: "v2",
+		},
+		{
+			name:     "Kubernetes",
+			mount:    "secret",
+			path:     "app",
+			opts:     Options{Auth: &kubernetes},
+			key:      "password",
+			expected: "v2",
+		},
+		{
+			name:  "Invalid token",
+			mount: "secret",
+			path:  "app",
+			opts:  Options{Auth: &badToken},
+			isErr: true,
+		},
+		{
+			name:  "Invalid AppRole",
+			mount: "secret",
+			path:  "app",
+			opts:  Options{Auth: &badAppRole},
+			isErr: true,
+		},
+		{
+			name:     "Secret not found",
+			mount:    "secret",
+			path:     "missing",
+			opts:     Options{Auth: &token},
+			isErr:    true,
+			notFound: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(&fakeVault{})
+			defer server.Close()
+
+			c.opts.Address = server.URL
+			config := cfg.New()
+			config.AddNamed("vault", NewLoader(c.mount, c.path, &c.opts), cfg.PriorityNormal)
+
+			err := config.Load()
+			if c.isErr {
+				if err == nil {
+					t.Fatal("No error when error expected")
+				} else if c.notFound != errors.Is(err, cfg.ErrNotFound) {
+					t.Errorf("Not found %v != %v", c.notFound, errors.Is(err, cfg.ErrNotFound))
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if actual := config.MustGetString(c.key); actual != c.expected {
+				t.Errorf("%s != %s", c.expected, actual)
+			}
+			if p, _ := config.Provenance(c.key); !p.Sensitive {
+				t.Errorf("%s is not sensitive", c.key)
+			}
+		})
+	}
+}
+
+func Test_VaultLoader_Watch(t *testing.T) {
+	fake := &fakeVault{renewDuration: 0}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	token := TokenAuth("root")
+	config := cfg.New()
+	config.Add(NewLoader("database", "creds/app", &Options{Address: server.URL, Auth: &token, KVVersion: 1}))
+	if err := config.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reloads := make(chan error, 1)
+	go func() {
+		_ = config.Watch(ctx, func(err error) { reloads <- err })
+	}()
+
+	// The lease is renewed once, but the renewed lease is shorter than requested so new credentials
+	// are loaded before it expires
+	select {
+	case err := <-reloads:
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Timed out waiting for reload")
+	}
+
+	if actual := config.MustGetString("username"); actual != "user2" {
+		t.Errorf("user2 != %s", actual)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.renewed != 1 {
+		t.Errorf("Renewed 1 != %d", fake.renewed)
+	}
+}
+
+func Test_VaultLoader_Watch_Signalled(t *testing.T) {
+	server := httptest.NewServer(&fakeVault{renewDuration: 0})
+	defer server.Close()
+
+	token := TokenAuth("root")
+	loader := NewLoader("database", "creds/app", &Options{Address: server.URL, Auth: &token, KVVersion: 1})
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := loader.Watch(ctx); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// The lease was already reported, so watching again blocks until the secret is loaded again
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := loader.Watch(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("%v != %v", context.DeadlineExceeded, err)
+	}
+}
+
+func Test_NewResolver(t *testing.T) {
+	server := httptest.NewServer(&fakeVault{})
+	defer server.Close()
+
+	token := TokenAuth("root")
+	config := cfg.New()
+	config.RegisterResolver("vault", NewResolver(&Options{Address: server.URL, Auth: &token}))
+	config.Add(&testLoader{data: map[string]any{
+		"db": map[string]any{"password": "vault://secret/app#password"},
+	}})
+	if err := config.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if actual := config.MustGetString("db:password"); actual != "v2" {
+		t.Errorf("v2 != %s", actual)
+	}
+	if p, _ := config.Provenance("db:password"); !p.Sensitive {
+		t.Error("db:password is not sensitive")
+	}
+
+	config.Add(&testLoader{data: map[string]any{"api": "vault://secret/app#missing"}})
+	if err := config.Load(); !errors.Is(err, cfg.ErrNotFound) {
+		t.Errorf("%v != %v", cfg.ErrNotFound, err)
+	}
+}
+
+type testLoader struct {
+	data map[string]any
+}
+
+func (l *testLoader) Load() (map[string]any, error) {
+	return l.data, nil
+}
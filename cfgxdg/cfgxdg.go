@@ -0,0 +1,221 @@
+package cfgxdg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/cfgjson"
+)
+
+// Scope of a configuration file, from the least to the most specific
+type Scope int
+
+const (
+	// Files shared by all users of the system e.g. /etc/app/config.json
+	ScopeSystem Scope = iota
+
+	// Files of the current user e.g. ~/.config/app/config.json
+	ScopeUser
+
+	// Files of the project in the working directory e.g. ./.app.json
+	ScopeProject
+)
+
+// Priorities of discovered files. Files sit below loaders added with normal priority, such as
+// environment variables, so that they can still be overridden, but above library defaults added
+// with cfg.PriorityLow, so that defaults never override a file
+const (
+	PrioritySystem  = cfg.PriorityLow + 10
+	PriorityUser    = cfg.PriorityLow + 20
+	PriorityProject = cfg.PriorityLow + 30
+)
+
+// Gets the name of the scope
+func (s Scope) String() string {
+	switch s {
+	case ScopeSystem:
+		return "system"
+	case ScopeUser:
+		return "user"
+	case ScopeProject:
+		return "project"
+	default:
+		return fmt.Sprintf("Scope(%d)", int(s))
+	}
+}
+
+func (s Scope) priority() cfg.Priority {
+	switch s {
+	case ScopeUser:
+		return PriorityUser
+	case ScopeProject:
+		return PriorityProject
+	default:
+		return PrioritySystem
+	}
+}
+
+// A location searched for a configuration file
+type Location struct {
+	Scope Scope
+	Path  string
+
+	// Set if a file exists at the path and was added to the configuration
+	Found bool
+}
+
+// Special options used to control where configuration files are searched for
+type Options struct {
+	// Name of the file inside system and user config directories. Defaults to "config.json"
+	FileName string
+
+	// Name of the file in the project directory. Defaults to ".<app>.json"
+	ProjectFileName string
+
+	// Directory searched for the project file. Defaults to the working directory
+	ProjectDir string
+
+	// Directory of system-wide configuration outside of XDG_CONFIG_DIRS. Defaults to "/etc"
+	EtcDir string
+
+	// Creates the loader for a file that was found. Defaults to a cfgjson loader, so the file may be
+	// removed after it is discovered without failing later loads
+	NewLoader func(path string) cfg.Loader
+}
+
+// Standard options that are used if none is provided
+var StandardOptions = Options{
+	FileName: "config.json",
+	EtcDir:   "/etc",
+}
+
+// Splits a list of directories from an XDG environment variable, using the default if it is unset
+// or empty. Relative paths are invalid and ignored
+func xdgDirs(name, def string) []string {
+	v := os.Getenv(name)
+	if v == "" {
+		v = def
+	}
+
+	dirs := make([]string, 0)
+	for _, dir := range filepath.SplitList(v) {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+// Gets every location searched for the app's configuration, from the lowest to the highest
+// precedence
+func Locations(app string, opts *Options) ([]Location, error) {
+	opts = withDefaults(app, opts)
+	locations := make([]Location, 0)
+
+	system := []string{filepath.Join(opts.EtcDir, app, opts.FileName)}
+
+	// XDG_CONFIG_DIRS is ordered from the most to the least important
+	configDirs := xdgDirs("XDG_CONFIG_DIRS", "/etc/xdg")
+	for i := len(configDirs) - 1; i >= 0; i-- {
+		system = append(system, filepath.Join(configDirs[i], app, opts.FileName))
+	}
+	seen := make(map[string]bool)
+	for _, path := range system {
+		// Directories may be listed twice e.g. if XDG_CONFIG_DIRS includes /etc
+		if !seen[path] {
+			seen[path] = true
+			locations = append(locations, Location{Scope: ScopeSystem, Path: path})
+		}
+	}
+
+	configHome := xdgDirs("XDG_CONFIG_HOME", "")
+	if len(configHome) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		configHome = []string{filepath.Join(home, ".config")}
+	}
+	locations = append(locations, Location{Scope: ScopeUser, Path: filepath.Join(configHome[0], app, opts.FileName)})
+
+	projectDir := opts.ProjectDir
+	if projectDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		projectDir = wd
+	}
+	locations = append(locations, Location{Scope: ScopeProject, Path: filepath.Join(projectDir, opts.ProjectFileName)})
+
+	return locations, nil
+}
+
+func withDefaults(app string, opts *Options) *Options {
+	if opts == nil {
+		opts = &StandardOptions
+	}
+
+	o := *opts
+	if o.FileName == "" {
+		o.FileName = StandardOptions.FileName
+	}
+	if o.ProjectFileName == "" {
+		o.ProjectFileName = fmt.Sprintf(".%s.json", strings.TrimPrefix(app, "."))
+	}
+	if o.EtcDir == "" {
+		o.EtcDir = StandardOptions.EtcDir
+	}
+	if o.NewLoader == nil {
+		o.NewLoader = func(path string) cfg.Loader {
+			return cfgjson.NewLoader(path, false)
+		}
+	}
+
+	return &o
+}
+
+// Finds configuration files for an app following XDG conventions and adds every file that exists to
+// the configuration. Searches, from the lowest to the highest precedence:
+//
+//   - /etc/<app>/config.json
+//   - <dir>/<app>/config.json for each directory in $XDG_CONFIG_DIRS (default /etc/xdg)
+//   - $XDG_CONFIG_HOME/<app>/config.json (default ~/.config)
+//   - ./.<app>.json
+//
+// System files are added with PrioritySystem, user files with PriorityUser and the project file with
+// PriorityProject, so more specific files override less specific ones. Each file is named
+// "xdg:<path>", and discovering again replaces loaders already added. Returns every location searched
+// and whether a file was found there. Uses the standard options if none is provided
+func Discover(config *cfg.Config, app string, opts *Options) ([]Location, error) {
+	locations, err := Locations(app, opts)
+	if err != nil {
+		return nil, err
+	}
+	opts = withDefaults(app, opts)
+
+	for i, loc := range locations {
+		info, err := os.Stat(loc.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		} else if info.IsDir() {
+			continue
+		}
+
+		locations[i].Found = true
+		name := "xdg:" + loc.Path
+		l := opts.NewLoader(loc.Path)
+		if err := config.Replace(name, l); err != nil {
+			config.AddNamed(name, l, loc.Scope.priority())
+		}
+	}
+
+	return locations, nil
+}
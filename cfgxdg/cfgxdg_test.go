@@ -0,0 +1,84 @@
+package cfgxdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jaredhughes1012/cfg"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
+
+func Test_Discover(t *testing.T) {
+	root := t.TempDir()
+	etc, xdgDirs, home, project := filepath.Join(root, "etc"), filepath.Join(root, "xdg"), filepath.Join(root, "home"), filepath.Join(root, "project")
+	t.Setenv("XDG_CONFIG_DIRS", xdgDirs+string(os.PathListSeparator)+"relative")
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	writeTestFile(t, filepath.Join(etc, "app", "config.json"), `{"a": "etc", "b": "etc", "c": "etc", "d": "etc"}`)
+	writeTestFile(t, filepath.Join(xdgDirs, "app", "config.json"), `{"b": "xdg", "c": "xdg", "d": "xdg"}`)
+	writeTestFile(t, filepath.Join(project, ".app.json"), `{"d": "project"}`)
+
+	config := cfg.New()
+	opts := &Options{EtcDir: etc, ProjectDir: project}
+	locations, err := Discover(config, "app", opts)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected := []Location{
+		{Scope: ScopeSystem, Path: filepath.Join(etc, "app", "config.json"), Found: true},
+		{Scope: ScopeSystem, Path: filepath.Join(xdgDirs, "app", "config.json"), Found: true},
+		{Scope: ScopeUser, Path: filepath.Join(home, "app", "config.json"), Found: false},
+		{Scope: ScopeProject, Path: filepath.Join(project, ".app.json"), Found: true},
+	}
+	if len(expected) != len(locations) {
+		t.Fatalf("%v != %v", expected, locations)
+	}
+	for i := range expected {
+		if expected[i] != locations[i] {
+			t.Errorf("%v != %v", expected[i], locations[i])
+		}
+	}
+
+	// Loaders added later with normal priority still override discovered files, but library
+	// defaults added later do not
+	writeTestFile(t, filepath.Join(home, "app", "config.json"), `{"c": "user", "d": "user"}`)
+	if _, err := Discover(config, "app", opts); err != nil {
+		t.Fatalf("%v", err)
+	}
+	config.Add(&testLoader{data: map[string]any{"e": "normal"}})
+	config.AddNamed("defaults", &testLoader{data: map[string]any{"a": "default", "f": "default"}}, cfg.PriorityLow)
+
+	if err := config.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for key, value := range map[string]string{"a": "etc", "b": "xdg", "c": "user", "d": "project", "e": "normal", "f": "default"} {
+		if actual := config.MustGetString(key); actual != value {
+			t.Errorf("%s %s != %s", key, value, actual)
+		}
+	}
+	if p, _ := config.Provenance("c"); p.Loader != "xdg:"+filepath.Join(home, "app", "config.json") {
+		t.Errorf("Unexpected provenance %s", p.Loader)
+	}
+	if len(config.Loaders()) != 6 {
+		t.Errorf("6 != %d", len(config.Loaders()))
+	}
+}
+
+type testLoader struct {
+	data map[string]any
+}
+
+func (l *testLoader) Load() (map[string]any, error) {
+	return l.data, nil
+}
@@ -0,0 +1,78 @@
+package cfg
+
+import (
+	"context"
+	"time"
+)
+
+// Loader that reads from a source which may be slow or remote, such as a network service. Loaders
+// implementing this interface are cancelled when the context passed to LoadContext is done.
+// Loaders may implement both this and Loader, in which case LoadContext is preferred
+type ContextLoader interface {
+	// Loads configuration from a source into a map, stopping early if the context is done
+	LoadContext(ctx context.Context) (map[string]any, error)
+}
+
+type loadResult struct {
+	data map[string]any
+	err  error
+}
+
+// Loads from a loader, using LoadContext if available. Loaders that do not accept a context are run
+// in the background so that the caller can stop waiting once the context is done, although the
+// loader itself will continue until it returns
+func loadContext(ctx context.Context, l Loader) (map[string]any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if cl, ok := l.(ContextLoader); ok {
+		return cl.LoadContext(ctx)
+	} else if ctx.Done() == nil {
+		return l.Load()
+	}
+
+	results := make(chan loadResult, 1)
+	go func() {
+		data, err := l.Load()
+		results <- loadResult{data: data, err: err}
+	}()
+
+	select {
+	case res := <-results:
+		return res.data, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type timeoutLoader struct {
+	loader  Loader
+	timeout time.Duration
+}
+
+// Loads configuration from a source into a map, bounded by the loader's timeout
+func (l *timeoutLoader) Load() (map[string]any, error) {
+	return l.LoadContext(context.Background())
+}
+
+// Loads configuration from a source into a map, bounded by the loader's timeout
+func (l *timeoutLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	return loadContext(ctx, l.loader)
+}
+
+// Gets the wrapped loader
+func (l *timeoutLoader) Unwrap() Loader {
+	return l.loader
+}
+
+// Wraps a loader so that it fails with context.DeadlineExceeded if it takes longer than the given
+// timeout
+func WithTimeout(l Loader, timeout time.Duration) Loader {
+	return &timeoutLoader{loader: l, timeout: timeout}
+}
+
+var _ ContextLoader = (*timeoutLoader)(nil)
@@ -0,0 +1,108 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type testContextLoader struct {
+	delay time.Duration
+	data  map[string]any
+}
+
+func (loader testContextLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+func (loader testContextLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	select {
+	case <-time.After(loader.delay):
+		return loader.data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type testSlowLoader struct {
+	delay time.Duration
+}
+
+func (loader testSlowLoader) Load() (map[string]any, error) {
+	time.Sleep(loader.delay)
+	return map[string]any{}, nil
+}
+
+func Test_Config_LoadContext(t *testing.T) {
+	cases := []struct {
+		name    string
+		loader  Loader
+		timeout time.Duration
+		err     error
+	}{
+		{
+			name:    "Context loader completes",
+			loader:  testContextLoader{delay: 0, data: testData},
+			timeout: time.Second,
+		},
+		{
+			name:    "Context loader cancelled",
+			loader:  testContextLoader{delay: time.Second, data: testData},
+			timeout: 10 * time.Millisecond,
+			err:     context.DeadlineExceeded,
+		},
+		{
+			name:    "Plain loader abandoned",
+			loader:  testSlowLoader{delay: time.Second},
+			timeout: 10 * time.Millisecond,
+			err:     context.DeadlineExceeded,
+		},
+		{
+			name:    "Cancellation ignores policy",
+			loader:  Optional(testContextLoader{delay: time.Second}),
+			timeout: 10 * time.Millisecond,
+			err:     context.DeadlineExceeded,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := New()
+			cfg.Add(c.loader)
+
+			ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := cfg.LoadContext(ctx)
+			if !errors.Is(err, c.err) {
+				t.Errorf("%v != %v", c.err, err)
+			}
+			if time.Since(start) > 500*time.Millisecond {
+				t.Errorf("Load took %v", time.Since(start))
+			}
+		})
+	}
+}
+
+func Test_WithTimeout(t *testing.T) {
+	cfg := New()
+	cfg.AddNamed("fast", WithTimeout(testContextLoader{delay: 0, data: testData}, time.Second), PriorityNormal)
+	cfg.AddNamed("slow", Optional(WithTimeout(testContextLoader{delay: time.Second}, 10*time.Millisecond)), PriorityNormal)
+
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	results := cfg.Report().Results
+	if results[0].Status != LoadSucceeded {
+		t.Errorf("Fast loader %s", results[0].Status)
+	}
+	if results[1].Status != LoadFailed || !errors.Is(results[1].Err, context.DeadlineExceeded) {
+		t.Errorf("Slow loader %s: %v", results[1].Status, results[1].Err)
+	}
+	if actual := cfg.MustGetString("key"); actual != "value" {
+		t.Errorf("Value value != %s", actual)
+	}
+}
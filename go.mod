@@ -1,3 +1,3 @@
 module github.com/jaredhughes1012/cfg
 
-go 1.18
+go 1.21
@@ -0,0 +1,64 @@
+package kv
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jaredhughes1012/cfg"
+	"github.com/jaredhughes1012/cfg/internal/mapconvert"
+)
+
+// Key and raw value read from a key-value store
+type Pair struct {
+	Key   string
+	Value []byte
+}
+
+// Converts key-value pairs into nested configuration. The prefix is removed from each key and the
+// remainder is split on "/" e.g. "app/db/host" with prefix "app/" is nested as db -> host. Keys
+// ending in "/" are treated as folders and skipped. Values of keys with an extension found in
+// decoders are decoded and nested under the key without its extension, and all other values are
+// loaded as strings
+func Nest(pairs []Pair, prefix string, decoders map[string]cfg.Decoder) (map[string]any, error) {
+	sorted := append([]Pair{}, pairs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	data := make(map[string]any)
+
+	for _, pair := range sorted {
+		if strings.HasSuffix(pair.Key, "/") {
+			continue
+		}
+
+		rel := strings.Trim(strings.TrimPrefix(pair.Key, prefix), "/")
+		var v any = string(pair.Value)
+
+		ext := path.Ext(pair.Key)
+		if decode, ok := decoders[ext]; ok {
+			decoded, err := decode(bytes.NewReader(pair.Value))
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", pair.Key, err)
+			}
+
+			rel, v = strings.TrimSuffix(rel, ext), decoded
+		}
+
+		entry := make(map[string]any)
+		if rel == "" {
+			// The prefix itself is a document
+			decoded, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			entry = decoded
+		} else {
+			mapconvert.SetPath(entry, strings.Split(rel, "/"), v)
+		}
+
+		data = mapconvert.Fold(entry, data)
+	}
+
+	return data, nil
+}
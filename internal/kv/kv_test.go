@@ -0,0 +1,77 @@
+package kv
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/jaredhughes1012/cfg"
+)
+
+func decodeJson(r io.Reader) (map[string]any, error) {
+	var data map[string]any
+	err := json.NewDecoder(r).Decode(&data)
+	return data, err
+}
+
+func Test_Nest(t *testing.T) {
+	decoders := map[string]cfg.Decoder{".json": decodeJson}
+	cases := []struct {
+		name     string
+		prefix   string
+		pairs    []Pair
+		expected map[string]any
+		isErr    bool
+	}{
+		{
+			name:   "Nested keys",
+			prefix: "app/",
+			pairs: []Pair{
+				{Key: "app/", Value: nil},
+				{Key: "app/db/host", Value: []byte("localhost")},
+				{Key: "app/name", Value: []byte("svc")},
+			},
+			expected: map[string]any{"db": map[string]any{"host": "localhost"}, "name": "svc"},
+		},
+		{
+			name:   "Decoded blob merged with keys",
+			prefix: "app/",
+			pairs: []Pair{
+				{Key: "app/db/port", Value: []byte("5432")},
+				{Key: "app/db.json", Value: []byte(`{"host": "localhost"}`)},
+			},
+			expected: map[string]any{"db": map[string]any{"host": "localhost", "port": "5432"}},
+		},
+		{
+			name:     "Prefix is a document",
+			prefix:   "app.json",
+			pairs:    []Pair{{Key: "app.json", Value: []byte(`{"name": "svc"}`)}},
+			expected: map[string]any{"name": "svc"},
+		},
+		{
+			name:   "Invalid blob",
+			prefix: "app/",
+			pairs:  []Pair{{Key: "app/db.json", Value: []byte(`{`)}},
+			isErr:  true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := Nest(c.pairs, c.prefix, decoders)
+			if c.isErr {
+				if err == nil {
+					t.Error("No error when error expected")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if !reflect.DeepEqual(c.expected, actual) {
+				t.Errorf("%v != %v", c.expected, actual)
+			}
+		})
+	}
+}
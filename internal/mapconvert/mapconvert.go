@@ -1,29 +1,71 @@
 package mapconvert
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
 
-// Moves all keys from the "from" map into the "onto" map, recursively. Any duplicate keys
-// will be overwritten by the "from" map
-func Fold(from, onto map[string]any) map[string]any {
-	newMap := make(map[string]any)
+// Escape character used for key segments that contain the delimiter
+const escape = '\\'
 
-	for k, v := range onto {
-		newMap[k] = v
+// Escapes a single key segment so that any delimiters or escape characters inside it are not
+// treated as separators e.g. "http://host" with delim ":" becomes "http\://host"
+func EscapeSegment(seg, delim string) string {
+	if !strings.ContainsRune(seg, escape) && !strings.Contains(seg, delim) {
+		return seg
 	}
 
-	for k, v := range from {
-		vMap1, ok := v.(map[string]any)
-		if ok {
-			vMap2, ok := newMap[k].(map[string]any)
-			if ok {
-				v = Fold(vMap1, vMap2)
+	seg = strings.ReplaceAll(seg, string(escape), string(escape)+string(escape))
+	return strings.ReplaceAll(seg, delim, string(escape)+delim)
+}
+
+// Joins key segments into a single key, escaping each segment
+func JoinKey(segs []string, delim string) string {
+	escaped := make([]string, len(segs))
+	for i, seg := range segs {
+		escaped[i] = EscapeSegment(seg, delim)
+	}
+
+	return strings.Join(escaped, delim)
+}
+
+// Splits a key into its segments, unescaping each segment. Reverses JoinKey
+func SplitKey(key, delim string) []string {
+	segs := make([]string, 0)
+	var sb strings.Builder
+
+	for i := 0; i < len(key); i++ {
+		if key[i] == escape && i+1 < len(key) {
+			if strings.HasPrefix(key[i+1:], delim) {
+				sb.WriteString(delim)
+				i += len(delim)
+				continue
+			} else if key[i+1] == escape {
+				sb.WriteByte(escape)
+				i++
+				continue
 			}
 		}
 
-		newMap[k] = v
+		if delim != "" && strings.HasPrefix(key[i:], delim) {
+			segs = append(segs, sb.String())
+			sb.Reset()
+			i += len(delim) - 1
+			continue
+		}
+
+		sb.WriteByte(key[i])
 	}
 
-	return newMap
+	return append(segs, sb.String())
+}
+
+// Moves all keys from the "from" map into the "onto" map, recursively. Any duplicate keys
+// will be overwritten by the "from" map, and keys with a nil value in the "from" map are deleted
+func Fold(from, onto map[string]any) map[string]any {
+	return FoldWith(from, onto, FoldOptions{})
 }
 
 func prefixFlatten(target, m map[string]any, prefix, delim string) {
@@ -32,18 +74,32 @@ func prefixFlatten(target, m map[string]any, prefix, delim string) {
 	}
 
 	for k, v := range m {
-		k := fmt.Sprintf("%s%s", prefix, k)
+		k := fmt.Sprintf("%s%s", prefix, EscapeSegment(k, delim))
 		if vMap, ok := v.(map[string]any); ok {
 			prefixFlatten(target, vMap, k, delim)
+		} else if vArr, ok := v.([]any); ok {
+			target[k] = v
+			prefixFlatten(target, arrayToMap(vArr), k, delim)
 		} else {
 			target[k] = v
 		}
 	}
 }
 
+func arrayToMap(arr []any) map[string]any {
+	m := make(map[string]any, len(arr))
+	for i, v := range arr {
+		m[strconv.Itoa(i)] = v
+	}
+
+	return m
+}
+
 // Converts the given map into a single map. All child maps have their keys appended to the
 // parent key and separated by a given delimiter e.g. { "child": { "key": "value"}} with
-// delim ":" becomes { "child:key": "value" }
+// delim ":" becomes { "child:key": "value" }. Keys containing the delimiter are escaped with
+// EscapeSegment. Arrays are kept at their own key and their elements are also flattened using
+// their index e.g. { "list": [{ "key": "value" }]} also contains { "list:0:key": "value" }
 func Flatten(m map[string]any, delim string) map[string]any {
 	target := make(map[string]any)
 	prefixFlatten(target, m, "", delim)
@@ -61,3 +117,145 @@ func ConvertKeys(m map[string]any, converter func(string) string) map[string]any
 
 	return target
 }
+
+// Splits keys that contain the delimiter into nested maps, so that {"db:port": 5432} with delim ":"
+// becomes {"db": {"port": 5432}}, recursing into nested maps and arrays. Escaped delimiters are
+// kept as part of the key. Maps produced by overlapping keys are merged, in sorted key order
+func ExpandKeys(m map[string]any, delim string) map[string]any {
+	target := make(map[string]any)
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		segs := SplitKey(k, delim)
+		parent := target
+		for _, seg := range segs[:len(segs)-1] {
+			child, ok := parent[seg].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				parent[seg] = child
+			}
+			parent = child
+		}
+
+		mergeValue(parent, segs[len(segs)-1], expandValue(m[k], delim))
+	}
+
+	return target
+}
+
+func expandValue(v any, delim string) any {
+	switch vt := v.(type) {
+	case map[string]any:
+		return ExpandKeys(vt, delim)
+	case []any:
+		arr := make([]any, len(vt))
+		for i, elem := range vt {
+			arr[i] = expandValue(elem, delim)
+		}
+		return arr
+	default:
+		return v
+	}
+}
+
+// Sets a key in the map, merging the value with an existing map at the key if both are maps.
+// Unlike Fold, nil values are kept
+func mergeValue(m map[string]any, k string, v any) {
+	if vMap, ok := v.(map[string]any); ok {
+		if existing, ok := m[k].(map[string]any); ok {
+			for ck, cv := range vMap {
+				mergeValue(existing, ck, cv)
+			}
+			return
+		}
+	}
+
+	m[k] = v
+}
+
+// Controls how keys are normalized
+type NormalizeOptions struct {
+	// If set, keys that normalize to the same key are merged instead of returning an error. Keys
+	// are applied in sorted order, so the value of the key that sorts last is kept
+	AllowDuplicates bool
+}
+
+// Converts all keys in the map and all nested maps, including maps inside arrays, using the
+// normalizer function. Returns an error if two distinct keys in the same map normalize to the
+// same key, as one value would silently replace the other
+func NormalizeKeys(m map[string]any, normalizer func(string) string) (map[string]any, error) {
+	return NormalizeKeysWith(m, normalizer, NormalizeOptions{})
+}
+
+// Converts all keys in the map and all nested maps like NormalizeKeys, using the given options
+func NormalizeKeysWith(m map[string]any, normalizer func(string) string, opts NormalizeOptions) (map[string]any, error) {
+	target := make(map[string]any)
+	sources := make(map[string]string)
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		nk := normalizer(k)
+		if prev, ok := sources[nk]; ok && !opts.AllowDuplicates {
+			return nil, fmt.Errorf("keys %q and %q both normalize to %q", prev, k, nk)
+		}
+		sources[nk] = k
+
+		v, err := normalizeValue(m[k], normalizer, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		mergeValue(target, nk, v)
+	}
+
+	return target, nil
+}
+
+// Converts all keys in a value of any type like NormalizeKeys, if it is a map or contains maps
+func NormalizeValue(v any, normalizer func(string) string) (any, error) {
+	return normalizeValue(v, normalizer, NormalizeOptions{})
+}
+
+func normalizeValue(v any, normalizer func(string) string, opts NormalizeOptions) (any, error) {
+	switch vt := v.(type) {
+	case map[string]any:
+		return NormalizeKeysWith(vt, normalizer, opts)
+	case []any:
+		arr := make([]any, len(vt))
+		for i, elem := range vt {
+			nv, err := normalizeValue(elem, normalizer, opts)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = nv
+		}
+		return arr, nil
+	default:
+		return v, nil
+	}
+}
+
+// Sets a value in the map at the given path of keys, creating nested maps as needed. Any value
+// that is not a map and lies along the path is replaced
+func SetPath(m map[string]any, path []string, v any) {
+	for _, k := range path[:len(path)-1] {
+		child, ok := m[k].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			m[k] = child
+		}
+		m = child
+	}
+
+	m[path[len(path)-1]] = v
+}
@@ -109,6 +109,52 @@ func Test_Fold(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Delete key",
+			from: map[string]any{
+				"test1": nil,
+			},
+			onto: map[string]any{
+				"test1": "val1",
+				"test2": "val2",
+			},
+			result: map[string]any{
+				"test2": "val2",
+			},
+		},
+		{
+			name: "Delete subtree",
+			from: map[string]any{
+				"test": map[string]any{
+					"child": nil,
+				},
+			},
+			onto: map[string]any{
+				"test": map[string]any{
+					"child": map[string]any{
+						"nested": "val",
+					},
+					"other": "val",
+				},
+			},
+			result: map[string]any{
+				"test": map[string]any{
+					"other": "val",
+				},
+			},
+		},
+		{
+			name: "Delete missing key",
+			from: map[string]any{
+				"test": map[string]any{
+					"child": nil,
+				},
+			},
+			onto: map[string]any{},
+			result: map[string]any{
+				"test": map[string]any{},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -186,3 +232,216 @@ func Test_ConvertKeys(t *testing.T) {
 
 	compareMaps(t, expected, actual)
 }
+
+func Test_NormalizeKeys(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    map[string]any
+		expected map[string]any
+		isErr    bool
+	}{
+		{
+			name: "Nested",
+			input: map[string]any{
+				"KEY": map[string]any{
+					"NESTED": "value",
+				},
+			},
+			expected: map[string]any{
+				"key": map[string]any{
+					"nested": "value",
+				},
+			},
+		},
+		{
+			name: "Collision",
+			input: map[string]any{
+				"key": "value1",
+				"KEY": "value2",
+			},
+			isErr: true,
+		},
+		{
+			name: "Nested collision",
+			input: map[string]any{
+				"parent": map[string]any{
+					"key": "value1",
+					"Key": "value2",
+				},
+			},
+			isErr: true,
+		},
+		{
+			name: "Same key in different maps",
+			input: map[string]any{
+				"key": "value1",
+				"parent": map[string]any{
+					"KEY": "value2",
+				},
+			},
+			expected: map[string]any{
+				"key": "value1",
+				"parent": map[string]any{
+					"key": "value2",
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NormalizeKeys(c.input, strings.ToLower)
+			if c.isErr {
+				if err == nil {
+					t.Error("No error when error expected")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			compareMaps(t, c.expected, actual)
+		})
+	}
+}
+
+func Test_ExpandKeys(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    map[string]any
+		expected map[string]any
+	}{
+		{
+			name:     "Flat key",
+			input:    map[string]any{"db:port": 5432},
+			expected: map[string]any{"db": map[string]any{"port": 5432}},
+		},
+		{
+			name: "Overlapping keys",
+			input: map[string]any{
+				"db":      map[string]any{"host": "localhost"},
+				"db:port": 5432,
+			},
+			expected: map[string]any{"db": map[string]any{"host": "localhost", "port": 5432}},
+		},
+		{
+			name:     "Escaped delimiter",
+			input:    map[string]any{`hosts:http\://host`: "val"},
+			expected: map[string]any{"hosts": map[string]any{"http://host": "val"}},
+		},
+		{
+			name:     "Nested and in arrays",
+			input:    map[string]any{"servers": []any{map[string]any{"tls:enabled": true}}},
+			expected: map[string]any{"servers": []any{map[string]any{"tls": map[string]any{"enabled": true}}}},
+		},
+		{
+			name:     "Nil value",
+			input:    map[string]any{"db:port": nil},
+			expected: map[string]any{"db": map[string]any{"port": nil}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			compareMaps(t, c.expected, ExpandKeys(c.input, ":"))
+		})
+	}
+}
+
+func Test_NormalizeKeysWith_AllowDuplicates(t *testing.T) {
+	input := map[string]any{
+		"HTTP_PROXY": "upper",
+		"http_proxy": "lower",
+		"DB":         map[string]any{"HOST": "localhost"},
+		"db":         map[string]any{"port": "5432"},
+	}
+	expected := map[string]any{
+		"http_proxy": "lower",
+		"db":         map[string]any{"host": "localhost", "port": "5432"},
+	}
+
+	actual, err := NormalizeKeysWith(input, strings.ToLower, NormalizeOptions{AllowDuplicates: true})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	compareMaps(t, expected, actual)
+}
+
+func Test_JoinKey_SplitKey(t *testing.T) {
+	cases := []struct {
+		name  string
+		segs  []string
+		delim string
+		key   string
+	}{
+		{name: "Plain", segs: []string{"one", "two"}, delim: ":", key: "one:two"},
+		{name: "Escaped delimiter", segs: []string{"hosts", "[::1]"}, delim: ":", key: `hosts:[\:\:1]`},
+		{name: "Escaped escape", segs: []string{`a\b`, "c"}, delim: ":", key: `a\\b:c`},
+		{name: "Dotted", segs: []string{"example.com", "port"}, delim: ".", key: `example\.com.port`},
+		{name: "Multi character delimiter", segs: []string{"a", "b__c"}, delim: "__", key: `a__b\__c`},
+		{name: "Empty segment", segs: []string{"a", "", "b"}, delim: ":", key: "a::b"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := JoinKey(c.segs, c.delim); c.key != actual {
+				t.Errorf("Key %s != %s", c.key, actual)
+			}
+
+			actual := SplitKey(c.key, c.delim)
+			if strings.Join(c.segs, "|") != strings.Join(actual, "|") {
+				t.Errorf("Segments %q != %q", c.segs, actual)
+			}
+		})
+	}
+}
+
+func Test_Flatten_Arrays(t *testing.T) {
+	input := map[string]any{
+		"servers": []any{
+			map[string]any{"host": "a.local"},
+			"b.local",
+		},
+	}
+	expected := map[string]any{
+		"servers":        input["servers"],
+		"servers:0:host": "a.local",
+		"servers:1":      "b.local",
+	}
+
+	compareMaps(t, expected, Flatten(input, ":"))
+}
+
+func Test_Flatten_Escaped(t *testing.T) {
+	input := map[string]any{
+		"hosts": map[string]any{
+			"http://host": "test",
+		},
+	}
+	expected := map[string]any{
+		`hosts:http\://host`: "test",
+	}
+
+	compareMaps(t, expected, Flatten(input, ":"))
+}
+
+func Test_SetPath(t *testing.T) {
+	m := map[string]any{
+		"a": "replaced",
+		"b": map[string]any{
+			"c": "kept",
+		},
+	}
+
+	SetPath(m, []string{"a", "x"}, "1")
+	SetPath(m, []string{"b", "d"}, "2")
+	SetPath(m, []string{"e"}, "3")
+
+	expected := map[string]any{
+		"a": map[string]any{"x": "1"},
+		"b": map[string]any{"c": "kept", "d": "2"},
+		"e": "3",
+	}
+	compareMaps(t, expected, m)
+}
@@ -0,0 +1,185 @@
+package mapconvert
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Controls how an array is combined with an existing array when folding
+type ArrayStrategy int
+
+const (
+	// The new array replaces the existing array
+	Replace ArrayStrategy = iota
+
+	// Elements of the new array are appended to the existing array
+	Append
+
+	// Elements are folded with the existing element at the same index. Extra elements in either
+	// array are kept, and nil elements leave the existing element unmodified
+	MergeByIndex
+
+	// Map elements are folded with the existing map element that has the same value for a key
+	// field. Elements without a match are appended
+	MergeByKey
+)
+
+// Describes how the array at a single key path is folded
+type MergeRule struct {
+	Strategy ArrayStrategy
+
+	// Field used to match elements when using MergeByKey
+	KeyField string
+}
+
+// Controls how maps are folded
+type FoldOptions struct {
+	// Delimiter used to join the key paths in Rules
+	Delim string
+
+	// Rules for arrays by key path. Arrays without a rule are replaced
+	Rules map[string]MergeRule
+}
+
+// Moves all keys from the "from" map into the "onto" map, recursively. Any duplicate keys will be
+// overwritten by the "from" map, except for arrays with a rule in the options, which are combined
+// with the existing array using the rule's strategy. Keys with a nil value in the "from" map are
+// tombstones, which delete the key and any values nested under it from the "onto" map. A map whose
+// keys are all array indices, such as one loaded from SERVERS__0__HOST, is folded onto an existing
+// array element by element
+func FoldWith(from, onto map[string]any, opts FoldOptions) map[string]any {
+	return foldPath(from, onto, nil, opts)
+}
+
+func foldPath(from, onto map[string]any, path []string, opts FoldOptions) map[string]any {
+	newMap := make(map[string]any)
+
+	for k, v := range onto {
+		newMap[k] = v
+	}
+
+	for k, v := range from {
+		if v == nil {
+			delete(newMap, k)
+			continue
+		}
+
+		newMap[k] = foldValue(v, newMap[k], append(path[:len(path):len(path)], k), opts)
+	}
+
+	return newMap
+}
+
+func foldValue(from, onto any, path []string, opts FoldOptions) any {
+	switch fromVal := from.(type) {
+	case map[string]any:
+		if ontoArr, ok := onto.([]any); ok {
+			if indices, ok := arrayIndices(fromVal, len(ontoArr)); ok {
+				return foldIndexed(fromVal, indices, ontoArr, path, opts)
+			}
+		}
+
+		// Always fold maps, even onto nothing, so that nil values in new maps are removed
+		ontoMap, _ := onto.(map[string]any)
+		return foldPath(fromVal, ontoMap, path, opts)
+	case []any:
+		if ontoArr, ok := onto.([]any); ok {
+			return foldArray(fromVal, ontoArr, path, opts)
+		}
+	}
+
+	return from
+}
+
+// Gets the array index of every key in the map if all keys are indices. Indices may extend an array
+// of length n by at most the number of keys, so that a single key cannot allocate a huge array
+func arrayIndices(m map[string]any, n int) (map[string]int, bool) {
+	if len(m) == 0 {
+		return nil, false
+	}
+
+	indices := make(map[string]int, len(m))
+	for k := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= n+len(m) || strconv.Itoa(i) != k {
+			return nil, false
+		}
+		indices[k] = i
+	}
+
+	return indices, true
+}
+
+// Folds each element of an index-addressed map with the existing element at the same index. Nil
+// elements leave the existing element unmodified, and gaps left by indices past the end of the
+// array are filled with nil
+func foldIndexed(from map[string]any, indices map[string]int, onto []any, path []string, opts FoldOptions) []any {
+	arr := make([]any, len(onto))
+	copy(arr, onto)
+
+	for k, v := range from {
+		i := indices[k]
+		for len(arr) <= i {
+			arr = append(arr, nil)
+		}
+		if v != nil {
+			arr[i] = foldValue(v, arr[i], append(path[:len(path):len(path)], k), opts)
+		}
+	}
+
+	return arr
+}
+
+func foldArray(from, onto []any, path []string, opts FoldOptions) []any {
+	rule := opts.Rules[JoinKey(path, opts.Delim)]
+
+	switch rule.Strategy {
+	case Append:
+		arr := make([]any, 0, len(onto)+len(from))
+		return append(append(arr, onto...), from...)
+	case MergeByIndex:
+		arr := make([]any, len(onto))
+		copy(arr, onto)
+		for i, v := range from {
+			if v == nil && i < len(arr) {
+				continue
+			} else if i < len(arr) {
+				arr[i] = foldValue(v, arr[i], append(path[:len(path):len(path)], fmt.Sprint(i)), opts)
+			} else {
+				arr = append(arr, v)
+			}
+		}
+		return arr
+	case MergeByKey:
+		arr := make([]any, len(onto))
+		copy(arr, onto)
+		for _, v := range from {
+			i := indexByKey(arr, v, rule.KeyField)
+			if i < 0 {
+				arr = append(arr, v)
+			} else {
+				arr[i] = foldValue(v, arr[i], append(path[:len(path):len(path)], fmt.Sprint(i)), opts)
+			}
+		}
+		return arr
+	default:
+		return from
+	}
+}
+
+// Finds the index of the map element in arr with the same key field value as elem, or -1 if there
+// is no match
+func indexByKey(arr []any, elem any, keyField string) int {
+	elemMap, ok := elem.(map[string]any)
+	if !ok || elemMap[keyField] == nil {
+		return -1
+	}
+
+	for i, v := range arr {
+		if vMap, ok := v.(map[string]any); ok && fmt.Sprint(vMap[keyField]) == fmt.Sprint(elemMap[keyField]) {
+			return i
+		}
+	}
+
+	return -1
+}
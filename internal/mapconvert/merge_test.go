@@ -0,0 +1,149 @@
+package mapconvert
+
+import (
+	"testing"
+)
+
+func Test_FoldWith(t *testing.T) {
+	onto := map[string]any{
+		"servers": []any{
+			map[string]any{"name": "a", "host": "a.local"},
+			map[string]any{"name": "b", "host": "b.local"},
+		},
+	}
+
+	cases := []struct {
+		name   string
+		from   map[string]any
+		rule   MergeRule
+		result map[string]any
+	}{
+		{
+			name: "Replace",
+			from: map[string]any{
+				"servers": []any{map[string]any{"name": "c"}},
+			},
+			rule: MergeRule{Strategy: Replace},
+			result: map[string]any{
+				"servers": []any{map[string]any{"name": "c"}},
+			},
+		},
+		{
+			name: "Append",
+			from: map[string]any{
+				"servers": []any{map[string]any{"name": "c"}},
+			},
+			rule: MergeRule{Strategy: Append},
+			result: map[string]any{
+				"servers": []any{
+					map[string]any{"name": "a", "host": "a.local"},
+					map[string]any{"name": "b", "host": "b.local"},
+					map[string]any{"name": "c"},
+				},
+			},
+		},
+		{
+			name: "Merge by index",
+			from: map[string]any{
+				"servers": []any{map[string]any{"host": "a.remote"}},
+			},
+			rule: MergeRule{Strategy: MergeByIndex},
+			result: map[string]any{
+				"servers": []any{
+					map[string]any{"name": "a", "host": "a.remote"},
+					map[string]any{"name": "b", "host": "b.local"},
+				},
+			},
+		},
+		{
+			name: "Merge by index placeholder",
+			from: map[string]any{
+				"servers": []any{nil, map[string]any{"host": "b.remote"}},
+			},
+			rule: MergeRule{Strategy: MergeByIndex},
+			result: map[string]any{
+				"servers": []any{
+					map[string]any{"name": "a", "host": "a.local"},
+					map[string]any{"name": "b", "host": "b.remote"},
+				},
+			},
+		},
+		{
+			name: "Index-addressed map",
+			from: map[string]any{
+				"servers": map[string]any{"1": map[string]any{"host": "b.remote"}},
+			},
+			rule: MergeRule{Strategy: Replace},
+			result: map[string]any{
+				"servers": []any{
+					map[string]any{"name": "a", "host": "a.local"},
+					map[string]any{"name": "b", "host": "b.remote"},
+				},
+			},
+		},
+		{
+			name: "Index-addressed map extends array",
+			from: map[string]any{
+				"servers": map[string]any{"2": map[string]any{"name": "c"}},
+			},
+			rule: MergeRule{Strategy: MergeByIndex},
+			result: map[string]any{
+				"servers": []any{
+					map[string]any{"name": "a", "host": "a.local"},
+					map[string]any{"name": "b", "host": "b.local"},
+					map[string]any{"name": "c"},
+				},
+			},
+		},
+		{
+			name: "Map with non-index keys",
+			from: map[string]any{
+				"servers": map[string]any{"0": map[string]any{"name": "c"}, "primary": "c"},
+			},
+			rule: MergeRule{Strategy: MergeByIndex},
+			result: map[string]any{
+				"servers": map[string]any{"0": map[string]any{"name": "c"}, "primary": "c"},
+			},
+		},
+		{
+			name: "Merge by key",
+			from: map[string]any{
+				"servers": []any{
+					map[string]any{"name": "b", "host": "b.remote"},
+					map[string]any{"name": "c", "host": "c.remote"},
+				},
+			},
+			rule: MergeRule{Strategy: MergeByKey, KeyField: "name"},
+			result: map[string]any{
+				"servers": []any{
+					map[string]any{"name": "a", "host": "a.local"},
+					map[string]any{"name": "b", "host": "b.remote"},
+					map[string]any{"name": "c", "host": "c.remote"},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			result := FoldWith(c.from, onto, FoldOptions{
+				Delim: ":",
+				Rules: map[string]MergeRule{"servers": c.rule},
+			})
+			compareMaps(t, c.result, result)
+		})
+	}
+}
+
+func Test_FoldWith_NoRule(t *testing.T) {
+	result := FoldWith(map[string]any{
+		"list": []any{"b"},
+	}, map[string]any{
+		"list": []any{"a"},
+	}, FoldOptions{
+		Delim: ":",
+		Rules: map[string]MergeRule{"other": {Strategy: Append}},
+	})
+
+	compareMaps(t, map[string]any{"list": []any{"b"}}, result)
+}
@@ -0,0 +1,215 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Subset of JSON Schema that maps onto declared fields
+type jsonSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Type        any                    `json:"type,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Default     any                    `json:"default,omitempty"`
+	Enum        []any                  `json:"enum,omitempty"`
+	Minimum     *float64               `json:"minimum,omitempty"`
+	Maximum     *float64               `json:"maximum,omitempty"`
+	MinLength   *float64               `json:"minLength,omitempty"`
+	MaxLength   *float64               `json:"maxLength,omitempty"`
+	Pattern     string                 `json:"pattern,omitempty"`
+	Format      string                 `json:"format,omitempty"`
+	WriteOnly   bool                   `json:"writeOnly,omitempty"`
+	Properties  map[string]*jsonSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+}
+
+// Gets the first non-null type name, as types may be given as a string or a list of strings
+func (s *jsonSchema) typeName() string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []any:
+		for _, name := range t {
+			if str, ok := name.(string); ok && str != "null" {
+				return str
+			}
+		}
+	}
+
+	return ""
+}
+
+func (s *jsonSchema) fieldType() Type {
+	switch s.typeName() {
+	case "string":
+		if s.WriteOnly || s.Format == "password" {
+			return TypeSecret
+		}
+		return TypeString
+	case "integer":
+		return TypeInt
+	case "number":
+		return TypeFloat64
+	case "boolean":
+		return TypeBool
+	default:
+		return TypeAny
+	}
+}
+
+func (s *jsonSchema) collectFields(prefix []string, required bool, key func(...string) string, fields *[]Field) {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := s.Properties[name]
+		segs := append(append([]string{}, prefix...), name)
+
+		// Nested values are only required if all of their parents are required
+		propRequired := false
+		for _, r := range s.Required {
+			propRequired = propRequired || (r == name && required)
+		}
+
+		if len(prop.Properties) > 0 {
+			prop.collectFields(segs, propRequired, key, fields)
+			continue
+		}
+
+		f := Field{
+			Key:         key(segs...),
+			Type:        prop.fieldType(),
+			Required:    propRequired,
+			Default:     prop.Default,
+			Allowed:     prop.Enum,
+			Min:         prop.Minimum,
+			Max:         prop.Maximum,
+			Pattern:     prop.Pattern,
+			Description: prop.Description,
+		}
+		if f.Type == TypeString || f.Type == TypeSecret {
+			f.Min, f.Max = prop.MinLength, prop.MaxLength
+		}
+
+		*fields = append(*fields, f)
+	}
+}
+
+// Reads a JSON Schema document and declares a field for every leaf property it describes. Nested
+// object properties are declared using their key path. Supports the type, description, default,
+// enum, minimum, maximum, minLength, maxLength, pattern and required keywords; other keywords are
+// ignored
+func (cfg *Config) DeclareJsonSchema(r io.Reader) error {
+	var schema jsonSchema
+	if err := json.NewDecoder(r).Decode(&schema); err != nil {
+		return fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	fields := make([]Field, 0)
+	schema.collectFields(nil, true, cfg.Key, &fields)
+	cfg.Declare(fields...)
+
+	return nil
+}
+
+// Adds a property to the required properties of the schema, if it is not already required
+func (s *jsonSchema) require(name string) {
+	for _, r := range s.Required {
+		if r == name {
+			return
+		}
+	}
+
+	s.Required = append(s.Required, name)
+}
+
+func jsonSchemaType(t Type) string {
+	switch t {
+	case TypeString, TypeSecret, TypeDuration:
+		return "string"
+	case TypeInt:
+		return "integer"
+	case TypeFloat64:
+		return "number"
+	case TypeBool:
+		return "boolean"
+	default:
+		return ""
+	}
+}
+
+// Generates a JSON Schema document describing the files that can be bound into the given struct
+// with BindStruct. Field descriptions are read from the "desc" tag and rules from the "validate" tag
+// are included as the equivalent JSON Schema keywords
+func StructJsonSchema(v any) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil, fmt.Errorf("cannot generate schema for nil")
+	}
+
+	fields, err := walkStruct(t)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &jsonSchema{
+		Schema:     jsonSchemaDraft,
+		Type:       "object",
+		Properties: make(map[string]*jsonSchema),
+	}
+
+	for _, f := range fields {
+		// Objects holding a required value are required too, as the value cannot be given without them
+		parent := root
+		for _, seg := range f.segs[:len(f.segs)-1] {
+			child, ok := parent.Properties[seg]
+			if !ok {
+				child = &jsonSchema{Type: "object", Properties: make(map[string]*jsonSchema)}
+				parent.Properties[seg] = child
+			}
+			if f.rules.Required {
+				parent.require(seg)
+			}
+			parent = child
+		}
+
+		name := f.segs[len(f.segs)-1]
+		prop := &jsonSchema{
+			Type:        jsonSchemaType(f.typ),
+			Description: f.description,
+			WriteOnly:   f.typ == TypeSecret,
+		}
+		for _, allowed := range f.rules.Allowed {
+			// Allowed values are parsed from tags as strings, but must match the type of the value
+			if prop.Type != "string" {
+				if converted, err := convertType(f.typ, f.path, allowed); err == nil {
+					allowed = converted
+				}
+			}
+			prop.Enum = append(prop.Enum, allowed)
+		}
+		if f.typ == TypeString || f.typ == TypeSecret {
+			prop.MinLength, prop.MaxLength = f.rules.Min, f.rules.Max
+		} else {
+			prop.Minimum, prop.Maximum = f.rules.Min, f.rules.Max
+		}
+		if f.rules.Required {
+			parent.require(name)
+		}
+
+		parent.Properties[name] = prop
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
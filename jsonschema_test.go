@@ -0,0 +1,207 @@
+package cfg
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testJsonSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["server"],
+	"properties": {
+		"level": {"type": "string", "enum": ["debug", "info"], "default": "info"},
+		"server": {
+			"type": "object",
+			"required": ["port"],
+			"properties": {
+				"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+				"host": {"type": ["string", "null"], "pattern": "^[a-z.]+$"}
+			}
+		},
+		"db": {
+			"type": "object",
+			"required": ["password"],
+			"properties": {
+				"password": {"type": "string", "writeOnly": true}
+			}
+		}
+	}
+}`
+
+func Test_Config_DeclareJsonSchema(t *testing.T) {
+	cases := []struct {
+		name       string
+		data       map[string]any
+		violations []string
+	}{
+		{
+			name: "Valid",
+			data: map[string]any{"server": map[string]any{"port": float64(80), "host": "localhost"}},
+		},
+		{
+			name:       "Missing required nested",
+			data:       map[string]any{},
+			violations: []string{"server:port is required"},
+		},
+		{
+			name: "Invalid values",
+			data: map[string]any{
+				"level":  "trace",
+				"server": map[string]any{"port": float64(0), "host": "LOCALHOST"},
+			},
+			violations: []string{
+				"level must be one of debug, info",
+				"server:host must match pattern ^[a-z.]+$",
+				"server:port must be at least 1",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := New()
+			cfg.Add(newTestLoader(c.data, nil))
+			if err := cfg.DeclareJsonSchema(strings.NewReader(testJsonSchema)); err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			err := cfg.Load()
+			var vErr *ValidationError
+			if len(c.violations) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error %v", err)
+				}
+				return
+			} else if !errors.As(err, &vErr) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+
+			if len(c.violations) != len(vErr.Violations) {
+				t.Fatalf("Violations %v != %v", c.violations, vErr.Violations)
+			}
+			for i, v := range vErr.Violations {
+				if c.violations[i] != v.String() {
+					t.Errorf("Violation %s != %s", c.violations[i], v.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_StructJsonSchema(t *testing.T) {
+	b, err := StructJsonSchema(&struct {
+		Name     string `desc:"Name of the service" validate:"required,max=20"`
+		Password Secret
+		Server   struct {
+			Port  int     `validate:"required,min=1,max=65535"`
+			Ratio float64 `cfg:"ratio" validate:"oneof=0.5 1"`
+		} `cfg:"server"`
+	}{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var actual map[string]any
+	if err := json.Unmarshal(b, &actual); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected := map[string]any{
+		"$schema":  jsonSchemaDraft,
+		"type":     "object",
+		"required": []any{"name", "server"},
+		"properties": map[string]any{
+			"name":     map[string]any{"type": "string", "description": "Name of the service", "maxLength": 20},
+			"password": map[string]any{"type": "string", "writeOnly": true},
+			"server": map[string]any{
+				"type":     "object",
+				"required": []any{"port"},
+				"properties": map[string]any{
+					"port":  map[string]any{"type": "integer", "minimum": 1, "maximum": 65535},
+					"ratio": map[string]any{"type": "number", "enum": []any{0.5, 1}},
+				},
+			},
+		},
+	}
+
+	expBytes, _ := json.Marshal(expected)
+	actBytes, _ := json.Marshal(actual)
+	if string(expBytes) != string(actBytes) {
+		t.Errorf("%s != %s", expBytes, actBytes)
+	}
+}
+
+func Test_StructJsonSchema_UnsupportedBound(t *testing.T) {
+	_, err := StructJsonSchema(&struct {
+		Debug bool `validate:"max=1"`
+	}{})
+	if err == nil {
+		t.Error("No error when error expected")
+	}
+}
+
+func Test_StructJsonSchema_RoundTrip(t *testing.T) {
+	b, err := StructJsonSchema(&struct {
+		Server struct {
+			Port int `validate:"required"`
+			Mode int `validate:"oneof=1 2"`
+		} `cfg:"server"`
+	}{})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	cases := []struct {
+		name       string
+		data       map[string]any
+		violations []string
+	}{
+		{
+			name: "Valid",
+			data: map[string]any{"server": map[string]any{"port": float64(80), "mode": float64(2)}},
+		},
+		{
+			name:       "Missing required nested",
+			data:       map[string]any{},
+			violations: []string{"server:port is required"},
+		},
+		{
+			name:       "Not allowed",
+			data:       map[string]any{"server": map[string]any{"port": float64(80), "mode": float64(3)}},
+			violations: []string{"server:mode must be one of 1, 2"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := New()
+			cfg.Add(newTestLoader(c.data, nil))
+			if err := cfg.DeclareJsonSchema(strings.NewReader(string(b))); err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			err := cfg.Load()
+			var vErr *ValidationError
+			if len(c.violations) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error %v", err)
+				}
+				return
+			} else if !errors.As(err, &vErr) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+
+			if len(c.violations) != len(vErr.Violations) {
+				t.Fatalf("Violations %v != %v", c.violations, vErr.Violations)
+			}
+			for i, v := range vErr.Violations {
+				if c.violations[i] != v.String() {
+					t.Errorf("Violation %s != %s", c.violations[i], v.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package cfg
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/jaredhughes1012/cfg/internal/mapconvert"
+)
+
+// Converts a single segment of a key path into the form used to store and look up values. Keys
+// from every loader and every key passed to a getter are normalized, so that sources using
+// different naming conventions can provide the same value
+type KeyNormalizer func(segment string) string
+
+// Lowercases keys and removes all underscores, so "MAX_CONNS", "max_conns" and "maxconns" are the
+// same key. This is the default
+func NormalizeStandard(segment string) string {
+	return strings.Replace(strings.ToLower(segment), "_", "", -1)
+}
+
+// Lowercases keys, so "MAX_CONNS" and "max_conns" are the same key but "maxconns" is distinct
+func NormalizeCaseInsensitive(segment string) string {
+	return strings.ToLower(segment)
+}
+
+// Leaves keys unmodified, so keys are case sensitive
+func NormalizeExact(segment string) string {
+	return segment
+}
+
+// Folds kebab, snake and camel case keys into lowercase snake case, so "max-conns", "MAX_CONNS",
+// "maxConns" and "MaxConns" are all "max_conns" but "maxconns" is distinct
+func NormalizeFold(segment string) string {
+	runes := []rune(segment)
+	var sb strings.Builder
+
+	for i, r := range runes {
+		if r == '-' || r == '_' || r == ' ' {
+			if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "_") {
+				sb.WriteRune('_')
+			}
+			continue
+		}
+
+		// Start a new word at lower to upper transitions and at the end of acronyms e.g. the "S"
+		// in "HTTPServer"
+		if unicode.IsUpper(r) && i > 0 && sb.Len() > 0 && !strings.HasSuffix(sb.String(), "_") {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				sb.WriteRune('_')
+			}
+		}
+
+		sb.WriteRune(unicode.ToLower(r))
+	}
+
+	return strings.TrimSuffix(sb.String(), "_")
+}
+
+// Loader whose source may provide the same key more than once in different forms, such as
+// environment variables that differ only in case e.g. HTTP_PROXY and http_proxy. Load fails when two
+// keys from other loaders normalize to the same key, but keys from these loaders are merged instead,
+// keeping the value of the key that sorts last
+type DuplicateKeysLoader interface {
+	// Reports whether keys that normalize to the same key are allowed
+	AllowDuplicateKeys() bool
+}
+
+// Reports whether the loader allows duplicate keys, looking through any wrappers
+func allowsDuplicateKeys(l Loader) bool {
+	d, ok := unwrapAs[DuplicateKeysLoader](l)
+	return ok && d.AllowDuplicateKeys()
+}
+
+// Loader that reads structured documents, such as JSON files or key value stores, whose keys are
+// used as they are. Keys from other loaders are split on the delimiter, but keys from these loaders
+// may contain it e.g. URLs or IPv6 addresses used as map keys
+type LiteralKeysLoader interface {
+	// Reports whether keys are used as they are instead of being split on the delimiter
+	LiteralKeys() bool
+}
+
+// Reports whether the loader's keys are used as they are, looking through any wrappers
+func hasLiteralKeys(l Loader) bool {
+	k, ok := unwrapAs[LiteralKeysLoader](l)
+	return ok && k.LiteralKeys()
+}
+
+// Sets the normalizer used for all keys. Takes effect the next time configuration is loaded
+func (cfg *Config) SetKeyNormalizer(normalizer KeyNormalizer) {
+	cfg.normalizer = normalizer
+}
+
+// Sets the delimiter used to separate the segments of key paths, which is ":" by default. Key
+// segments that contain the delimiter can be escaped with a backslash e.g. with the default
+// delimiter, the key "http://host" inside the map "hosts" is accessed with "hosts:http\://host".
+// Flat keys provided by loaders are split on the delimiter too, unless the loader implements
+// LiteralKeysLoader. Takes effect the next time configuration is loaded
+func (cfg *Config) SetDelimiter(delim string) {
+	cfg.delimiter = delim
+}
+
+// Joins key segments into a key path using this configuration's delimiter, escaping any segments
+// that contain the delimiter
+func (cfg *Config) Key(segs ...string) string {
+	return mapconvert.JoinKey(segs, cfg.delim())
+}
+
+func (cfg *Config) delim() string {
+	if cfg.delimiter == "" {
+		return ":"
+	}
+
+	return cfg.delimiter
+}
+
+// Splits a key path into unescaped segments using this configuration's delimiter
+func (cfg *Config) splitKey(key string) []string {
+	return mapconvert.SplitKey(key, cfg.delim())
+}
+
+func (cfg *Config) normalizeSegment(seg string) string {
+	if cfg.normalizer == nil {
+		return NormalizeStandard(seg)
+	}
+
+	return cfg.normalizer(seg)
+}
+
+// Normalizes every segment of a key path
+func (cfg *Config) normalizeKey(key string) string {
+	segs := cfg.splitKey(key)
+	for i, seg := range segs {
+		segs[i] = cfg.normalizeSegment(seg)
+	}
+
+	return cfg.Key(segs...)
+}
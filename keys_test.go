@@ -0,0 +1,151 @@
+package cfg
+
+import (
+	"testing"
+)
+
+func Test_KeyNormalizers(t *testing.T) {
+	cases := []struct {
+		name       string
+		normalizer KeyNormalizer
+		input      string
+		expected   string
+	}{
+		{name: "Standard", normalizer: NormalizeStandard, input: "MAX_CONNS", expected: "maxconns"},
+		{name: "Case insensitive", normalizer: NormalizeCaseInsensitive, input: "MAX_CONNS", expected: "max_conns"},
+		{name: "Exact", normalizer: NormalizeExact, input: "MAX_CONNS", expected: "MAX_CONNS"},
+		{name: "Fold snake", normalizer: NormalizeFold, input: "MAX_CONNS", expected: "max_conns"},
+		{name: "Fold kebab", normalizer: NormalizeFold, input: "max-conns", expected: "max_conns"},
+		{name: "Fold camel", normalizer: NormalizeFold, input: "maxConns", expected: "max_conns"},
+		{name: "Fold pascal", normalizer: NormalizeFold, input: "MaxConns", expected: "max_conns"},
+		{name: "Fold acronym", normalizer: NormalizeFold, input: "HTTPServer", expected: "http_server"},
+		{name: "Fold digits", normalizer: NormalizeFold, input: "ipv6Addr", expected: "ipv6_addr"},
+		{name: "Fold single word", normalizer: NormalizeFold, input: "maxconns", expected: "maxconns"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := c.normalizer(c.input); c.expected != actual {
+				t.Errorf("Key %s != %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Config_SetKeyNormalizer(t *testing.T) {
+	cases := []struct {
+		name       string
+		normalizer KeyNormalizer
+		data       []map[string]any
+		key        string
+		expected   string
+		isErr      bool
+	}{
+		{
+			name:       "Standard collision",
+			normalizer: NormalizeStandard,
+			data:       []map[string]any{{"max_conns": "1", "maxconns": "2"}},
+			isErr:      true,
+		},
+		{
+			name:       "Fold no collision",
+			normalizer: NormalizeFold,
+			data:       []map[string]any{{"max_conns": "1", "maxconns": "2"}},
+			key:        "maxConns",
+			expected:   "1",
+		},
+		{
+			name:       "Fold override across loaders",
+			normalizer: NormalizeFold,
+			data:       []map[string]any{{"maxConns": "1"}, {"MAX_CONNS": "2"}},
+			key:        "max-conns",
+			expected:   "2",
+		},
+		{
+			name:       "Exact",
+			normalizer: NormalizeExact,
+			data:       []map[string]any{{"Key": "1", "key": "2"}},
+			key:        "Key",
+			expected:   "1",
+		},
+		{
+			name:       "Case insensitive lookup",
+			normalizer: NormalizeCaseInsensitive,
+			data:       []map[string]any{{"parent": map[string]any{"Key": "1"}}},
+			key:        "PARENT:KEY",
+			expected:   "1",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := New()
+			cfg.SetKeyNormalizer(c.normalizer)
+			for _, d := range c.data {
+				cfg.Add(newTestLoader(d, nil))
+			}
+
+			err := cfg.Load()
+			if c.isErr {
+				if err == nil {
+					t.Error("No error when error expected")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if actual := cfg.MustGetString(c.key); c.expected != actual {
+				t.Errorf("Value %s != %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Config_SetDelimiter(t *testing.T) {
+	cfg := New()
+	cfg.SetDelimiter(".")
+	cfg.Add(newTestLoader(map[string]any{
+		"db": map[string]any{
+			"port": 5432,
+		},
+		"hosts": map[string]any{
+			`example\.com`: "1.2.3.4",
+			"[::1]":        "localhost",
+		},
+		"cache.ttl": 60,
+	}, nil))
+	cfg.Declare(Field{Key: "db.host", Type: TypeString, Default: "localhost"})
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if actual := cfg.MustGetInt("db.port"); actual != 5432 {
+		t.Errorf("Port 5432 != %d", actual)
+	}
+	if actual := cfg.MustGetString("db.host"); actual != "localhost" {
+		t.Errorf("Host localhost != %s", actual)
+	}
+	if actual := cfg.MustGetString(cfg.Key("hosts", "example.com")); actual != "1.2.3.4" {
+		t.Errorf("Host 1.2.3.4 != %s", actual)
+	}
+	if actual := cfg.MustGetString("hosts.[::1]"); actual != "localhost" {
+		t.Errorf("Host localhost != %s", actual)
+	}
+	if actual := cfg.MustGetInt("cache.ttl"); actual != 60 {
+		t.Errorf("TTL 60 != %d", actual)
+	}
+	if _, err := cfg.GetInt("db:port"); err == nil {
+		t.Error("No error when error expected")
+	}
+
+	var settings struct {
+		DB struct {
+			Port int `cfg:"port" validate:"max=1000"`
+		} `cfg:"db"`
+	}
+	err := cfg.BindStruct(&settings)
+	if err == nil || err.Error() != "invalid configuration: db.port (DB.Port) must be at most 1000" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
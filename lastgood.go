@@ -0,0 +1,87 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Keeps the last data successfully loaded by the named loader and uses it in place of the loader's
+// data if a later load fails, so that a temporarily unavailable source does not fail a restart or
+// reload. Values from cached data are marked as stale in their provenance.
+//
+// If cacheFile is not empty, the data is also written to that file after every successful load and
+// read back from it when no data has been loaded by this process yet. The file is written with
+// owner-only permissions as it may contain secrets. Returns an error if no loader is registered with
+// the name
+func (cfg *Config) KeepLastGood(name, cacheFile string) error {
+	i := cfg.indexOf(name)
+	if name == "" || i < 0 {
+		return fmt.Errorf("loader %s not found", name)
+	}
+
+	cfg.loaders[i].keepLastGood = true
+	cfg.loaders[i].cacheFile = cacheFile
+	return nil
+}
+
+// Gets the last data successfully loaded by this loader, from memory or from its cache file
+func (entry *loaderEntry) loadLastGood() (map[string]any, bool) {
+	if !entry.keepLastGood {
+		return nil, false
+	} else if entry.lastGood != nil {
+		return entry.lastGood, true
+	} else if entry.cacheFile == "" {
+		return nil, false
+	}
+
+	b, err := os.ReadFile(entry.cacheFile)
+	if err != nil {
+		return nil, false
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, false
+	}
+
+	entry.lastGood = data
+	return data, true
+}
+
+// Stores data that was successfully loaded by this loader. Returns an error if the cache file could
+// not be written
+func (entry *loaderEntry) storeLastGood(data map[string]any) error {
+	if !entry.keepLastGood {
+		return nil
+	}
+
+	entry.lastGood = data
+	if entry.cacheFile == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to write cache for loader %s: %w", entry.name, err)
+	}
+
+	// Write to a temporary file first so that a crash never leaves a partially written cache
+	tmp, err := os.CreateTemp(filepath.Dir(entry.cacheFile), filepath.Base(entry.cacheFile)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache for loader %s: %w", entry.name, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache for loader %s: %w", entry.name, err)
+	} else if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache for loader %s: %w", entry.name, err)
+	} else if err := os.Rename(tmp.Name(), entry.cacheFile); err != nil {
+		return fmt.Errorf("failed to write cache for loader %s: %w", entry.name, err)
+	}
+
+	return nil
+}
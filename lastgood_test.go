@@ -0,0 +1,145 @@
+package cfg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_Config_KeepLastGood(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "remote.json")
+	remote := newTestLoader(map[string]any{"db": map[string]any{"host": "db.remote"}}, nil)
+
+	cfg := New()
+	cfg.AddNamed("base", newTestLoader(map[string]any{"name": "svc"}, nil), PriorityLow)
+	cfg.AddNamed("remote", remote, PriorityNormal)
+	if err := cfg.KeepLastGood("remote", cacheFile); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if p, _ := cfg.Provenance("db:host"); p.Loader != "remote" || p.Stale {
+		t.Errorf("Unexpected provenance %v", p)
+	}
+	if _, err := os.Stat(cacheFile); err != nil {
+		t.Errorf("Cache file not written: %v", err)
+	}
+
+	// Fails after a successful load, falls back to memory
+	remote.data, remote.err = nil, errTest
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if actual := cfg.MustGetString("db:host"); actual != "db.remote" {
+		t.Errorf("Host db.remote != %s", actual)
+	}
+	if p, _ := cfg.Provenance("db:host"); p.Loader != "remote" || !p.Stale {
+		t.Errorf("Unexpected provenance %v", p)
+	}
+	if p, _ := cfg.Provenance("name"); p.Loader != "base" || p.Stale {
+		t.Errorf("Unexpected provenance %v", p)
+	}
+	if res := cfg.Report().Results[1]; res.Status != LoadStale || res.Err != errTest {
+		t.Errorf("Unexpected result %v", res)
+	}
+
+	// Fails in a new process, falls back to the cache file
+	restarted := New()
+	restarted.AddNamed("remote", newTestLoader(nil, errTest), PriorityNormal)
+	if err := restarted.KeepLastGood("remote", cacheFile); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if actual := restarted.MustGetString("db:host"); actual != "db.remote" {
+		t.Errorf("Host db.remote != %s", actual)
+	}
+}
+
+func Test_Config_KeepLastGood_NoData(t *testing.T) {
+	cfg := New()
+	cfg.AddNamed("remote", newTestLoader(nil, errTest), PriorityNormal)
+	if err := cfg.KeepLastGood("remote", filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if err := cfg.Load(); err != errTest {
+		t.Errorf("%v != %v", errTest, err)
+	}
+	if err := cfg.KeepLastGood("missing", ""); err == nil {
+		t.Error("No error when error expected")
+	}
+}
+
+func Test_Config_Provenance(t *testing.T) {
+	cfg, err := newConfigAndLoad(
+		newTestLoader(map[string]any{"a": "1", "b": "1", "c": "1"}, nil),
+		newTestLoader(map[string]any{"b": "2", "c": nil}, nil),
+	)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if _, ok := cfg.Provenance("a"); !ok {
+		t.Error("Provenance for a not found")
+	}
+	if _, ok := cfg.Provenance("c"); ok {
+		t.Error("Provenance found for deleted key")
+	}
+	if _, ok := cfg.Provenance("missing"); ok {
+		t.Error("Provenance found for missing key")
+	}
+}
+
+type testSensitiveLoader struct {
+	testLoader
+}
+
+func (testSensitiveLoader) Sensitive() bool {
+	return true
+}
+
+func Test_Config_Provenance_Sensitive(t *testing.T) {
+	cfg := New()
+	cfg.Add(newTestLoader(map[string]any{"a": "1", "b": "1"}, nil))
+	cfg.Add(Optional(&testSensitiveLoader{testLoader{data: map[string]any{"b": "2"}}}))
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if p, _ := cfg.Provenance("a"); p.Sensitive {
+		t.Error("a is sensitive")
+	}
+	if p, _ := cfg.Provenance("b"); !p.Sensitive {
+		t.Error("b is not sensitive")
+	}
+}
+
+type testVersionedLoader struct {
+	testLoader
+	version string
+}
+
+func (l testVersionedLoader) Version() string {
+	return l.version
+}
+
+func Test_Config_Provenance_Version(t *testing.T) {
+	cfg := New()
+	cfg.Add(newTestLoader(map[string]any{"a": "1"}, nil))
+	cfg.Add(WithTimeout(&testVersionedLoader{testLoader{data: map[string]any{"b": "2"}}, "abc123"}, time.Second))
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if p, _ := cfg.Provenance("a"); p.Version != "" {
+		t.Errorf("%s != ", p.Version)
+	}
+	if p, _ := cfg.Provenance("b"); p.Version != "abc123" {
+		t.Errorf("abc123 != %s", p.Version)
+	}
+}
@@ -0,0 +1,151 @@
+package cfg
+
+import "fmt"
+
+// Controls the order in which loaders are applied. Loaders with a higher priority override values
+// from loaders with a lower priority, and loaders with the same priority are applied in the order
+// they were added
+type Priority int
+
+const (
+	// Intended for defaults contributed by libraries, so they sit below the application's sources
+	PriorityLow Priority = -100
+
+	// Priority of loaders added with Add
+	PriorityNormal Priority = 0
+
+	// Intended for sources that should override all others, such as environment variables
+	PriorityHigh Priority = 100
+)
+
+// Describes a registered loader
+type LoaderInfo struct {
+	Name     string
+	Priority Priority
+}
+
+type loaderEntry struct {
+	name     string
+	loader   Loader
+	priority Priority
+
+	// Last known good data, if enabled with KeepLastGood
+	keepLastGood bool
+	cacheFile    string
+	lastGood     map[string]any
+}
+
+// Gets the loader as the given interface, looking through wrappers such as WithPolicy, WithTimeout
+// and Retry that implement Unwrap
+func unwrapAs[T any](l Loader) (T, bool) {
+	for l != nil {
+		if t, ok := l.(T); ok {
+			return t, true
+		}
+
+		u, ok := l.(interface{ Unwrap() Loader })
+		if !ok {
+			break
+		}
+		l = u.Unwrap()
+	}
+
+	var zero T
+	return zero, false
+}
+
+func (cfg *Config) indexOf(name string) int {
+	for i, entry := range cfg.loaders {
+		if entry.name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (cfg *Config) insertAt(i int, entry *loaderEntry) {
+	cfg.loaders = append(cfg.loaders, nil)
+	copy(cfg.loaders[i+1:], cfg.loaders[i:])
+	cfg.loaders[i] = entry
+}
+
+func (cfg *Config) checkName(name string) {
+	if name != "" && cfg.indexOf(name) >= 0 {
+		panic(fmt.Sprintf("loader %s is already registered", name))
+	}
+}
+
+// Adds a new config loader with a name and priority. The loader is applied after all loaders with
+// the same or lower priority that are already registered. Names are used to find the loader later
+// and must be unique; registering a name twice panics. An empty name registers an anonymous loader
+func (cfg *Config) AddNamed(name string, l Loader, priority Priority) {
+	cfg.checkName(name)
+
+	i := len(cfg.loaders)
+	for i > 0 && cfg.loaders[i-1].priority > priority {
+		i--
+	}
+
+	cfg.insertAt(i, &loaderEntry{name: name, loader: l, priority: priority})
+}
+
+// Adds a new named loader immediately before an existing loader, with the same priority. Returns an
+// error if no loader is registered with the target name
+func (cfg *Config) InsertBefore(target, name string, l Loader) error {
+	i := cfg.indexOf(target)
+	if target == "" || i < 0 {
+		return fmt.Errorf("loader %s not found", target)
+	}
+
+	cfg.checkName(name)
+	cfg.insertAt(i, &loaderEntry{name: name, loader: l, priority: cfg.loaders[i].priority})
+	return nil
+}
+
+// Adds a new named loader immediately after an existing loader, with the same priority. Returns an
+// error if no loader is registered with the target name
+func (cfg *Config) InsertAfter(target, name string, l Loader) error {
+	i := cfg.indexOf(target)
+	if target == "" || i < 0 {
+		return fmt.Errorf("loader %s not found", target)
+	}
+
+	cfg.checkName(name)
+	cfg.insertAt(i+1, &loaderEntry{name: name, loader: l, priority: cfg.loaders[i].priority})
+	return nil
+}
+
+// Replaces the loader registered with the given name, keeping its name, priority and position.
+// Returns an error if no loader is registered with the name
+func (cfg *Config) Replace(name string, l Loader) error {
+	i := cfg.indexOf(name)
+	if name == "" || i < 0 {
+		return fmt.Errorf("loader %s not found", name)
+	}
+
+	cfg.loaders[i].loader = l
+	return nil
+}
+
+// Removes the loader registered with the given name. Returns false if no loader is registered with
+// the name
+func (cfg *Config) Remove(name string) bool {
+	i := cfg.indexOf(name)
+	if name == "" || i < 0 {
+		return false
+	}
+
+	cfg.loaders = append(cfg.loaders[:i], cfg.loaders[i+1:]...)
+	return true
+}
+
+// Lists all registered loaders in the order they are applied, from lowest to highest precedence
+func (cfg *Config) Loaders() []LoaderInfo {
+	infos := make([]LoaderInfo, len(cfg.loaders))
+	for i, entry := range cfg.loaders {
+		infos[i] = LoaderInfo{Name: entry.name, Priority: entry.priority}
+	}
+
+	return infos
+}
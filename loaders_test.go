@@ -0,0 +1,143 @@
+package cfg
+
+import (
+	"strings"
+	"testing"
+)
+
+func loaderOrder(cfg *Config) string {
+	names := make([]string, 0)
+	for _, info := range cfg.Loaders() {
+		names = append(names, info.Name)
+	}
+
+	return strings.Join(names, ",")
+}
+
+func Test_Config_AddNamed(t *testing.T) {
+	cfg := New()
+	cfg.AddNamed("env", newTestLoader(map[string]any{"key": "env"}, nil), PriorityHigh)
+	cfg.AddNamed("file", newTestLoader(map[string]any{"key": "file"}, nil), PriorityNormal)
+	cfg.AddNamed("lib", newTestLoader(map[string]any{"key": "lib", "other": "lib"}, nil), PriorityLow)
+	cfg.AddNamed("overlay", newTestLoader(map[string]any{"key": "overlay"}, nil), PriorityNormal)
+
+	if expected, actual := "lib,file,overlay,env", loaderOrder(cfg); expected != actual {
+		t.Errorf("Order %s != %s", expected, actual)
+	}
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if actual := cfg.MustGetString("key"); actual != "env" {
+		t.Errorf("Value env != %s", actual)
+	}
+	if actual := cfg.MustGetString("other"); actual != "lib" {
+		t.Errorf("Value lib != %s", actual)
+	}
+}
+
+func Test_Config_AddNamed_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("No panic when panic expected")
+		}
+	}()
+
+	cfg := New()
+	cfg.AddNamed("env", newTestLoader(nil, nil), PriorityHigh)
+	cfg.AddNamed("env", newTestLoader(nil, nil), PriorityHigh)
+}
+
+func Test_Config_ModifyLoaders(t *testing.T) {
+	cases := []struct {
+		name     string
+		modify   func(cfg *Config) error
+		expected string
+		isErr    bool
+	}{
+		{
+			name: "Insert before",
+			modify: func(cfg *Config) error {
+				return cfg.InsertBefore("b", "x", newTestLoader(nil, nil))
+			},
+			expected: "a,x,b,c",
+		},
+		{
+			name: "Insert after",
+			modify: func(cfg *Config) error {
+				return cfg.InsertAfter("b", "x", newTestLoader(nil, nil))
+			},
+			expected: "a,b,x,c",
+		},
+		{
+			name: "Insert missing",
+			modify: func(cfg *Config) error {
+				return cfg.InsertAfter("missing", "x", newTestLoader(nil, nil))
+			},
+			isErr: true,
+		},
+		{
+			name: "Replace",
+			modify: func(cfg *Config) error {
+				return cfg.Replace("b", newTestLoader(nil, nil))
+			},
+			expected: "a,b,c",
+		},
+		{
+			name: "Replace missing",
+			modify: func(cfg *Config) error {
+				return cfg.Replace("missing", newTestLoader(nil, nil))
+			},
+			isErr: true,
+		},
+		{
+			name: "Remove",
+			modify: func(cfg *Config) error {
+				cfg.Remove("b")
+				return nil
+			},
+			expected: "a,c",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := New()
+			cfg.AddNamed("a", newTestLoader(nil, nil), PriorityLow)
+			cfg.AddNamed("b", newTestLoader(nil, nil), PriorityNormal)
+			cfg.AddNamed("c", newTestLoader(nil, nil), PriorityHigh)
+
+			err := c.modify(cfg)
+			if c.isErr {
+				if err == nil {
+					t.Error("No error when error expected")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			if actual := loaderOrder(cfg); c.expected != actual {
+				t.Errorf("Order %s != %s", c.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_Config_InsertBefore_Priority(t *testing.T) {
+	cfg := New()
+	cfg.AddNamed("env", newTestLoader(map[string]any{"key": "env"}, nil), PriorityHigh)
+	if err := cfg.InsertBefore("env", "secrets", newTestLoader(map[string]any{"key": "secrets"}, nil)); err != nil {
+		t.Fatalf("%v", err)
+	}
+	cfg.AddNamed("file", newTestLoader(map[string]any{"key": "file"}, nil), PriorityNormal)
+
+	if expected, actual := "file,secrets,env", loaderOrder(cfg); expected != actual {
+		t.Errorf("Order %s != %s", expected, actual)
+	}
+	if actual := cfg.Loaders()[1].Priority; actual != PriorityHigh {
+		t.Errorf("Priority %d != %d", PriorityHigh, actual)
+	}
+	if !cfg.Remove("secrets") || cfg.Remove("secrets") {
+		t.Error("Remove did not report removal correctly")
+	}
+}
@@ -0,0 +1,46 @@
+package cfg
+
+import "github.com/jaredhughes1012/cfg/internal/mapconvert"
+
+// Controls how an array provided by a loader is combined with the same array from loaders added
+// before it
+type MergeStrategy struct {
+	rule mapconvert.MergeRule
+}
+
+var (
+	// The array replaces the existing array. This is the default for all arrays
+	MergeReplace = MergeStrategy{rule: mapconvert.MergeRule{Strategy: mapconvert.Replace}}
+
+	// Elements are appended to the existing array
+	MergeAppend = MergeStrategy{rule: mapconvert.MergeRule{Strategy: mapconvert.Append}}
+
+	// Elements are merged with the existing element at the same index, so an overlay can modify a
+	// single element of a list by providing an array with only that element set
+	MergeByIndex = MergeStrategy{rule: mapconvert.MergeRule{Strategy: mapconvert.MergeByIndex}}
+)
+
+// Merges object elements with the existing element that has the same value for the given field.
+// Elements without a match are appended
+func MergeByKey(field string) MergeStrategy {
+	return MergeStrategy{rule: mapconvert.MergeRule{Strategy: mapconvert.MergeByKey, KeyField: field}}
+}
+
+// Sets the strategy used to merge the array at the given key path when multiple loaders provide
+// it. Takes effect the next time configuration is loaded
+func (cfg *Config) SetMergeStrategy(key string, strategy MergeStrategy) {
+	cfg.mergeRules[key] = strategy
+}
+
+// Gets the merge rules by normalized key path. Keys are normalized when loading so that rules
+// follow the key normalizer and delimiter in effect at the time
+func (cfg *Config) normalizedMergeRules() map[string]mapconvert.MergeRule {
+	rules := make(map[string]mapconvert.MergeRule, len(cfg.mergeRules))
+	for key, strategy := range cfg.mergeRules {
+		rule := strategy.rule
+		rule.KeyField = cfg.normalizeSegment(rule.KeyField)
+		rules[cfg.normalizeKey(key)] = rule
+	}
+
+	return rules
+}
@@ -0,0 +1,100 @@
+package cfg
+
+import (
+	"testing"
+)
+
+func Test_Config_SetMergeStrategy(t *testing.T) {
+	base := map[string]any{
+		"Servers": []any{
+			map[string]any{"Name": "a", "Host": "a.local"},
+			map[string]any{"Name": "b", "Host": "b.local"},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		strategy *MergeStrategy
+		overlay  map[string]any
+		expected map[string]string
+		missing  []string
+	}{
+		{
+			name:     "Default replace",
+			overlay:  map[string]any{"servers": []any{map[string]any{"host": "c.local"}}},
+			expected: map[string]string{"servers:0:host": "c.local"},
+			missing:  []string{"servers:0:name", "servers:1:host"},
+		},
+		{
+			name:     "Append",
+			strategy: &MergeAppend,
+			overlay:  map[string]any{"servers": []any{map[string]any{"host": "c.local"}}},
+			expected: map[string]string{"servers:0:host": "a.local", "servers:2:host": "c.local"},
+		},
+		{
+			name:     "Merge by index",
+			strategy: &MergeByIndex,
+			overlay:  map[string]any{"servers": []any{map[string]any{"host": "a.remote"}}},
+			expected: map[string]string{"servers:0:name": "a", "servers:0:host": "a.remote", "servers:1:host": "b.local"},
+		},
+		{
+			name:     "Index-addressed overlay",
+			overlay:  map[string]any{"servers": map[string]any{"1": map[string]any{"host": "b.remote"}}},
+			expected: map[string]string{"servers:0:host": "a.local", "servers:1:name": "b", "servers:1:host": "b.remote"},
+		},
+		{
+			name:     "Index-addressed overlay merged by index",
+			strategy: &MergeByIndex,
+			overlay:  map[string]any{"servers": map[string]any{"0": map[string]any{"port": "8080"}}},
+			expected: map[string]string{"servers:0:host": "a.local", "servers:0:port": "8080", "servers:1:host": "b.local"},
+		},
+		{
+			name:     "Merge by key",
+			strategy: func() *MergeStrategy { s := MergeByKey("NAME"); return &s }(),
+			overlay:  map[string]any{"servers": []any{map[string]any{"name": "b", "host": "b.remote"}}},
+			expected: map[string]string{"servers:0:host": "a.local", "servers:1:host": "b.remote"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := New()
+			if c.strategy != nil {
+				cfg.SetMergeStrategy("SERVERS", *c.strategy)
+			}
+			cfg.Add(newTestLoader(base, nil))
+			cfg.Add(newTestLoader(c.overlay, nil))
+			if err := cfg.Load(); err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			for key, expected := range c.expected {
+				if actual, err := cfg.GetString(key); err != nil || expected != actual {
+					t.Errorf("Key %s: %s != %s (%v)", key, expected, actual, err)
+				}
+			}
+			for _, key := range c.missing {
+				if _, err := cfg.GetString(key); err == nil {
+					t.Errorf("Key %s found when not expected", key)
+				}
+			}
+		})
+	}
+}
+
+func Test_Config_SetMergeStrategy_Normalizer(t *testing.T) {
+	cfg := New()
+	cfg.SetMergeStrategy("Servers", MergeAppend)
+	cfg.SetKeyNormalizer(NormalizeExact)
+	cfg.Add(newTestLoader(map[string]any{"Servers": []any{"a"}}, nil))
+	cfg.Add(newTestLoader(map[string]any{"Servers": []any{"b"}}, nil))
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for key, expected := range map[string]string{"Servers:0": "a", "Servers:1": "b"} {
+		if actual, err := cfg.GetString(key); err != nil || expected != actual {
+			t.Errorf("Key %s: %s != %s (%v)", key, expected, actual, err)
+		}
+	}
+}
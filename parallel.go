@@ -0,0 +1,51 @@
+package cfg
+
+import "context"
+
+// Sets the maximum number of loaders that run at the same time during Load. The default of 1 runs
+// loaders one at a time and stops at the first failure. Higher values run loaders concurrently,
+// and values below 1 run all loaders at once. Results are always merged in priority order so
+// precedence does not depend on which loader finishes first, and when loaders run concurrently
+// every failure is collected into a single error
+func (cfg *Config) SetConcurrency(n int) {
+	cfg.concurrency = n
+}
+
+// Starts running registered loaders and returns a function that waits for the result of the loader
+// at an index. When running serially, each loader is only run once its result is requested
+func (cfg *Config) startLoaders(ctx context.Context) func(int) loadResult {
+	entries := cfg.loaders
+	if cfg.concurrency == 1 {
+		return func(i int) loadResult {
+			data, err := loadContext(ctx, entries[i].loader)
+			return loadResult{data: data, err: err}
+		}
+	}
+
+	limit := cfg.concurrency
+	if limit < 1 || limit > len(entries) {
+		limit = len(entries)
+	}
+
+	results := make([]chan loadResult, len(entries))
+	for i := range results {
+		results[i] = make(chan loadResult, 1)
+	}
+
+	// Acquire slots in priority order so that loaders start in the same order they are merged
+	go func() {
+		sem := make(chan struct{}, limit)
+		for i, entry := range entries {
+			sem <- struct{}{}
+			go func(l Loader, out chan<- loadResult) {
+				defer func() { <-sem }()
+				data, err := loadContext(ctx, l)
+				out <- loadResult{data: data, err: err}
+			}(entry.loader, results[i])
+		}
+	}()
+
+	return func(i int) loadResult {
+		return <-results[i]
+	}
+}
@@ -0,0 +1,91 @@
+package cfg
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testCountingLoader struct {
+	delay   time.Duration
+	data    map[string]any
+	err     error
+	running *int32
+	peak    *int32
+}
+
+func (loader testCountingLoader) Load() (map[string]any, error) {
+	n := atomic.AddInt32(loader.running, 1)
+	defer atomic.AddInt32(loader.running, -1)
+	for {
+		peak := atomic.LoadInt32(loader.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(loader.peak, peak, n) {
+			break
+		}
+	}
+
+	time.Sleep(loader.delay)
+	return loader.data, loader.err
+}
+
+func Test_Config_SetConcurrency(t *testing.T) {
+	cases := []struct {
+		name        string
+		concurrency int
+		peak        int32
+	}{
+		{name: "Serial", concurrency: 1, peak: 1},
+		{name: "Bounded", concurrency: 2, peak: 2},
+		{name: "Unbounded", concurrency: 0, peak: 4},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var running, peak int32
+			cfg := New()
+			cfg.SetConcurrency(c.concurrency)
+
+			// Earlier loaders are slower, so they finish last but must still be overridden
+			for i := 0; i < 4; i++ {
+				cfg.Add(testCountingLoader{
+					delay:   time.Duration(4-i) * 20 * time.Millisecond,
+					data:    map[string]any{"key": i},
+					running: &running,
+					peak:    &peak,
+				})
+			}
+
+			if err := cfg.Load(); err != nil {
+				t.Fatalf("%v", err)
+			}
+			if actual := cfg.MustGetInt("key"); actual != 3 {
+				t.Errorf("Value 3 != %d", actual)
+			}
+			if c.peak != peak {
+				t.Errorf("Peak concurrency %d != %d", c.peak, peak)
+			}
+		})
+	}
+}
+
+func Test_Config_SetConcurrency_Errors(t *testing.T) {
+	errOther := errors.New("other")
+	var running, peak int32
+
+	cfg := New()
+	cfg.SetConcurrency(0)
+	cfg.AddNamed("a", testCountingLoader{err: errTest, running: &running, peak: &peak}, PriorityNormal)
+	cfg.AddNamed("b", testCountingLoader{data: testData, running: &running, peak: &peak}, PriorityNormal)
+	cfg.AddNamed("c", testCountingLoader{err: errOther, running: &running, peak: &peak}, PriorityNormal)
+
+	err := cfg.Load()
+	if !errors.Is(err, errTest) || !errors.Is(err, errOther) {
+		t.Errorf("Errors not aggregated: %v", err)
+	}
+
+	results := cfg.Report().Results
+	if len(results) != 3 || results[1].Status != LoadSucceeded {
+		t.Errorf("Unexpected results %v", results)
+	}
+}
@@ -0,0 +1,135 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+)
+
+// Returned by loaders when their source does not exist. Errors wrapping fs.ErrNotExist, such as
+// those from os.Open, are also treated as not found
+var ErrNotFound = errors.New("config source not found")
+
+// Controls how errors from a loader are handled during Load. Policies can be combined with |
+type Policy int
+
+const (
+	// Skips the loader if its source is not found rather than failing
+	IgnoreNotFound Policy = 1 << iota
+
+	// Records any other error in the load report rather than failing
+	WarnOnError
+)
+
+// Outcome of a single loader during Load
+type LoadStatus int
+
+const (
+	LoadSucceeded LoadStatus = iota
+	LoadSkipped
+	LoadFailed
+
+	// The loader failed and the last data it successfully loaded was used instead
+	LoadStale
+)
+
+func (s LoadStatus) String() string {
+	switch s {
+	case LoadSucceeded:
+		return "succeeded"
+	case LoadSkipped:
+		return "skipped"
+	case LoadStale:
+		return "stale"
+	default:
+		return "failed"
+	}
+}
+
+// Describes the outcome of a single loader during Load
+type LoaderResult struct {
+	Name   string
+	Status LoadStatus
+
+	// Error returned by the loader, if any. Set for skipped and stale loaders, for failures that
+	// were ignored because of the loader's policy, and for successful loaders whose data could not
+	// be written to their cache file
+	Err error
+}
+
+// Describes the outcome of every loader during the most recent Load, in the order loaders were
+// applied
+type LoadReport struct {
+	Results []LoaderResult
+}
+
+// Gets the results of loaders that did not succeed
+func (r LoadReport) Problems() []LoaderResult {
+	problems := make([]LoaderResult, 0)
+	for _, res := range r.Results {
+		if res.Status != LoadSucceeded {
+			problems = append(problems, res)
+		}
+	}
+
+	return problems
+}
+
+type policyLoader struct {
+	Loader
+	policy Policy
+}
+
+// Loads configuration from a source into a map, passing the context to the wrapped loader
+func (l *policyLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	return loadContext(ctx, l.Loader)
+}
+
+// Gets the wrapped loader
+func (l *policyLoader) Unwrap() Loader {
+	return l.Loader
+}
+
+// Wraps a loader so that its errors are handled according to the given policy instead of failing
+// Load. The policy applies even if the loader is wrapped again, such as with WithTimeout or Retry
+func WithPolicy(l Loader, p Policy) Loader {
+	return &policyLoader{Loader: l, policy: p}
+}
+
+// Wraps a loader so that Load continues if it fails. Missing sources are skipped and any other
+// errors are recorded in the load report
+func Optional(l Loader) Loader {
+	return WithPolicy(l, IgnoreNotFound|WarnOnError)
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, fs.ErrNotExist)
+}
+
+// Determines the outcome of a loader error based on the loader's policy. Returns true if the error
+// should fail Load
+func applyPolicy(ctx context.Context, l Loader, err error) (LoadStatus, bool) {
+	// Cancellation of the whole load is always fatal, regardless of the loader's policy
+	if ctx.Err() != nil {
+		return LoadFailed, true
+	}
+
+	var policy Policy
+	if pl, ok := unwrapAs[*policyLoader](l); ok {
+		policy = pl.policy
+	}
+
+	if policy&IgnoreNotFound != 0 && isNotFound(err) {
+		return LoadSkipped, false
+	}
+
+	return LoadFailed, policy&WarnOnError == 0
+}
+
+// Gets the report from the most recent Load. Use LoadWithReport to get the report of a specific load,
+// as another load may finish before Report is called
+func (cfg *Config) Report() LoadReport {
+	cfg.state.RLock()
+	defer cfg.state.RUnlock()
+	return cfg.report
+}
@@ -0,0 +1,137 @@
+package cfg
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_Config_Load_Policy(t *testing.T) {
+	errMissing := fmt.Errorf("open file: %w", os.ErrNotExist)
+
+	cases := []struct {
+		name     string
+		loader   Loader
+		status   LoadStatus
+		isErr    bool
+		problems int
+	}{
+		{
+			name:   "No policy",
+			loader: newTestLoader(nil, errTest),
+			status: LoadFailed,
+			isErr:  true,
+		},
+		{
+			name:     "Ignore not found",
+			loader:   WithPolicy(newTestLoader(nil, errMissing), IgnoreNotFound),
+			status:   LoadSkipped,
+			problems: 1,
+		},
+		{
+			name:     "Ignore not found sentinel",
+			loader:   WithPolicy(newTestLoader(nil, ErrNotFound), IgnoreNotFound),
+			status:   LoadSkipped,
+			problems: 1,
+		},
+		{
+			name:   "Ignore not found other error",
+			loader: WithPolicy(newTestLoader(nil, errTest), IgnoreNotFound),
+			status: LoadFailed,
+			isErr:  true,
+		},
+		{
+			name:     "Warn on error",
+			loader:   WithPolicy(newTestLoader(nil, errTest), WarnOnError),
+			status:   LoadFailed,
+			problems: 1,
+		},
+		{
+			name:     "Optional not found",
+			loader:   Optional(newTestLoader(nil, errMissing)),
+			status:   LoadSkipped,
+			problems: 1,
+		},
+		{
+			name:     "Optional error",
+			loader:   Optional(newTestLoader(nil, errTest)),
+			status:   LoadFailed,
+			problems: 1,
+		},
+		{
+			name:     "Optional inside timeout",
+			loader:   WithTimeout(Optional(newTestLoader(nil, errMissing)), time.Second),
+			status:   LoadSkipped,
+			problems: 1,
+		},
+		{
+			name:     "Optional inside retry",
+			loader:   Retry(Optional(newTestLoader(nil, errTest)), RetryPolicy{MaxAttempts: 1}),
+			status:   LoadFailed,
+			problems: 1,
+		},
+		{
+			name:   "Optional success",
+			loader: Optional(newTestLoader(map[string]any{"key": "optional"}, nil)),
+			status: LoadSucceeded,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := New()
+			cfg.AddNamed("base", newTestLoader(map[string]any{"key": "base"}, nil), PriorityLow)
+			cfg.AddNamed("test", c.loader, PriorityNormal)
+
+			err := cfg.Load()
+			if c.isErr && err == nil {
+				t.Error("No error when error expected")
+			} else if !c.isErr && err != nil {
+				t.Errorf("Unexpected error %v", err)
+			}
+
+			results := cfg.Report().Results
+			if len(results) != 2 {
+				t.Fatalf("Results %v", results)
+			}
+			if results[0].Name != "base" || results[0].Status != LoadSucceeded {
+				t.Errorf("Unexpected base result %v", results[0])
+			}
+			if results[1].Name != "test" || results[1].Status != c.status {
+				t.Errorf("Status %s != %s", c.status, results[1].Status)
+			}
+			if !c.isErr && len(cfg.Report().Problems()) != c.problems {
+				t.Errorf("Problems %d != %d", c.problems, len(cfg.Report().Problems()))
+			}
+		})
+	}
+}
+
+func Test_Config_LoadWithReport(t *testing.T) {
+	cfg := New()
+	cfg.AddNamed("remote", WithPolicy(newTestLoader(nil, errTest), WarnOnError), PriorityNormal)
+
+	first, err := cfg.LoadWithReport()
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if problems := first.Problems(); len(problems) != 1 || problems[0].Name != "remote" {
+		t.Errorf("Unexpected problems %v", problems)
+	}
+
+	cfg.AddNamed("failing", newTestLoader(nil, errTest), PriorityNormal)
+	second, err := cfg.LoadWithReport()
+	if err == nil {
+		t.Error("No error when error expected")
+	}
+	if len(second.Results) != 2 || second.Results[1].Status != LoadFailed {
+		t.Errorf("Unexpected results %v", second.Results)
+	}
+	if len(first.Results) != 1 {
+		t.Errorf("First report changed to %v", first.Results)
+	}
+	if actual := cfg.Report(); len(actual.Results) != len(second.Results) {
+		t.Errorf("Report %v != %v", second.Results, actual.Results)
+	}
+}
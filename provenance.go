@@ -0,0 +1,53 @@
+package cfg
+
+// Describes where a loaded value came from
+type Provenance struct {
+	// Name of the loader that provided the value. Empty for loaders added without a name
+	Loader string
+
+	// Set if the loader failed and the value came from the last data it successfully loaded
+	Stale bool
+
+	// Set if the loader reports that its values are sensitive, such as secrets read from a vault.
+	// Sensitive values should be redacted when configuration is printed or logged
+	Sensitive bool
+
+	// Version of the source reported by the loader, such as the commit the values were read from
+	Version string
+}
+
+// Loader that reads from a versioned source, such as a git repository
+type VersionedLoader interface {
+	// Gets the version of the source that was last loaded successfully
+	Version() string
+}
+
+// Gets the version of the source last loaded by the loader, looking through any wrappers
+func loaderVersion(l Loader) string {
+	if v, ok := unwrapAs[VersionedLoader](l); ok {
+		return v.Version()
+	}
+
+	return ""
+}
+
+// Loader whose values are sensitive, such as a secrets manager
+type SensitiveLoader interface {
+	// Reports whether all values from this loader are sensitive
+	Sensitive() bool
+}
+
+// Reports whether values from the loader are sensitive, looking through any wrappers
+func isSensitive(l Loader) bool {
+	s, ok := unwrapAs[SensitiveLoader](l)
+	return ok && s.Sensitive()
+}
+
+// Gets the provenance of the value at the given key from the most recent load. Returns false if the
+// key was not provided by any loader
+func (cfg *Config) Provenance(key string) (Provenance, bool) {
+	cfg.state.RLock()
+	defer cfg.state.RUnlock()
+	p, ok := cfg.provenance[cfg.normalizeKey(key)]
+	return p, ok
+}
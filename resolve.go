@@ -0,0 +1,160 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jaredhughes1012/cfg/internal/mapconvert"
+)
+
+// Resolves a reference to a value stored in an external source, such as "env://DB_PASS". Resolvers
+// are registered by URI scheme with RegisterResolver
+type Resolver func(ctx context.Context, ref *url.URL) (any, error)
+
+// Resolves "file:///path" references to the contents of the file, without trailing newlines, such
+// as secrets mounted by an orchestrator
+func FileResolver(ctx context.Context, ref *url.URL) (any, error) {
+	b, err := os.ReadFile(ref.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", ref.Path, ErrNotFound)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// Resolves "env://NAME" references to the value of the environment variable
+func EnvResolver(ctx context.Context, ref *url.URL) (any, error) {
+	v, ok := os.LookupEnv(ref.Host)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s: %w", ref.Host, ErrNotFound)
+	}
+
+	return v, nil
+}
+
+// Registers a resolver for references with the given URI scheme, replacing any resolver already
+// registered for it, or removes the resolver if r is nil. After sources are merged during Load,
+// every string value of the form "scheme://..." with a registered scheme is replaced with the value
+// it refers to, and the value is marked as sensitive in its provenance. Keys of maps returned by a
+// resolver are normalized, and every value inside them is marked as sensitive. No resolvers are
+// registered by default, so FileResolver and EnvResolver must be registered to be used
+func (cfg *Config) RegisterResolver(scheme string, r Resolver) {
+	scheme = strings.ToLower(scheme)
+	if r == nil {
+		delete(cfg.resolvers, scheme)
+	} else {
+		cfg.resolvers[scheme] = r
+	}
+}
+
+// Restricts resolving references to values provided by the loaders with the given names, so that
+// sources the application does not control cannot read local files or secrets through a resolver.
+// Values from other loaders are left as is. By default references from every loader are resolved
+func (cfg *Config) TrustLoaders(names ...string) {
+	cfg.trusted = make(map[string]bool, len(names))
+	for _, name := range names {
+		cfg.trusted[name] = true
+	}
+}
+
+// Gets the reference in a value, if the value is a string using the scheme of a registered resolver
+func (cfg *Config) reference(v any) (*url.URL, Resolver, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, nil, false
+	}
+
+	scheme, _, ok := strings.Cut(s, "://")
+	if !ok {
+		return nil, nil, false
+	}
+
+	r, ok := cfg.resolvers[strings.ToLower(scheme)]
+	if !ok {
+		return nil, nil, false
+	}
+
+	ref, err := url.Parse(s)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	return ref, r, true
+}
+
+// Replaces references in the merged data with the values they refer to, recursing into nested maps
+// and arrays. References are only resolved if the loader that provided them is trusted. Returns the
+// keys of all resolved values, and an error listing every reference that could not be resolved
+
+func (cfg *Config) resolve(ctx context.Context, data map[string]any, provenance map[string]Provenance) ([]string, error) {
+	resolved := make([]string, 0)
+	errs := make([]error, 0)
+
+	var walk func(v any, path []string) any
+	walk = func(v any, path []string) any {
+		switch vt := v.(type) {
+		case map[string]any:
+			for k, child := range vt {
+				vt[k] = walk(child, append(append([]string{}, path...), k))
+			}
+			return vt
+		case []any:
+			for i, child := range vt {
+				vt[i] = walk(child, append(append([]string{}, path...), strconv.Itoa(i)))
+			}
+			return vt
+		}
+
+		ref, r, ok := cfg.reference(v)
+		if !ok {
+			return v
+		}
+
+		key := mapconvert.JoinKey(path, cfg.delim())
+		if cfg.trusted != nil && !cfg.trusted[provenance[key].Loader] {
+			return v
+		}
+
+		value, err := r(ctx, ref)
+		if err == nil {
+			// Resolvers may return maps, such as every field of a secret, whose keys are
+			// normalized like keys from loaders
+			value, err = mapconvert.NormalizeValue(value, cfg.normalizeSegment)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolve %s: %w", key, err))
+			return v
+		}
+
+		resolved = append(resolved, key)
+		return value
+	}
+
+	walk(data, nil)
+	return resolved, errors.Join(errs...)
+}
+
+// Marks every value resolved from a reference as sensitive, including every key inside resolved maps
+// and arrays, which take the provenance of the reference
+func (cfg *Config) markResolved(data map[string]any, provenance map[string]Provenance, resolved []string) {
+	for _, key := range resolved {
+		p, ok := provenance[key]
+		if !ok {
+			continue
+		}
+		p.Sensitive = true
+
+		for k := range data {
+			if k == key || strings.HasPrefix(k, key+cfg.delim()) {
+				provenance[k] = p
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Config_Load_Resolve(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "db")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("%v", err)
+	}
+	t.Setenv("CFG_TEST_DB_PASS", "from-env")
+
+	cases := []struct {
+		name      string
+		data      map[string]any
+		register  map[string]Resolver
+		key       string
+		expected  string
+		sensitive bool
+		isErr     bool
+		notFound  bool
+	}{
+		{
+			name:      "File",
+			data:      map[string]any{"db": map[string]any{"password": "file://" + secretFile}},
+			register:  map[string]Resolver{"file": FileResolver},
+			key:       "db:password",
+			expected:  "from-file",
+			sensitive: true,
+		},
+		{
+			name:      "Environment variable",
+			data:      map[string]any{"db": map[string]any{"password": "env://CFG_TEST_DB_PASS"}},
+			register:  map[string]Resolver{"env": EnvResolver},
+			key:       "db:password",
+			expected:  "from-env",
+			sensitive: true,
+		},
+		{
+			name: "Custom resolver",
+			data: map[string]any{"hosts": []any{"vault://secret/db#host"}},
+			register: map[string]Resolver{"vault": func(ctx context.Context, ref *url.URL) (any, error) {
+				return ref.Host + ref.Path + ":" + ref.Fragment, nil
+			}},
+			key:       "hosts:0",
+			expected:  "secret/db:host",
+			sensitive: true,
+		},
+		{
+			name: "Map resolver",
+			data: map[string]any{"db": "vault://secret/db"},
+			register: map[string]Resolver{"vault": func(ctx context.Context, ref *url.URL) (any, error) {
+				return map[string]any{"User_Name": "admin", "Password": "secret"}, nil
+			}},
+			key:       "db:username",
+			expected:  "admin",
+			sensitive: true,
+		},
+		{
+			name:     "Unregistered scheme",
+			data:     map[string]any{"url": "http://localhost"},
+			key:      "url",
+			expected: "http://localhost",
+		},
+		{
+			name:     "No default resolvers",
+			data:     map[string]any{"dsn": "file:///var/lib/app/data.db", "password": "env://CFG_TEST_DB_PASS"},
+			key:      "dsn",
+			expected: "file:///var/lib/app/data.db",
+		},
+		{
+			name:     "Removed resolver",
+			data:     map[string]any{"password": "env://CFG_TEST_DB_PASS"},
+			register: map[string]Resolver{"env": nil},
+			key:      "password",
+			expected: "env://CFG_TEST_DB_PASS",
+		},
+		{
+			name:     "Missing environment variable",
+			data:     map[string]any{"password": "env://CFG_TEST_MISSING"},
+			register: map[string]Resolver{"env": EnvResolver},
+			isErr:    true,
+			notFound: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := New()
+			for scheme, r := range c.register {
+				cfg.RegisterResolver(scheme, r)
+			}
+			cfg.Add(newTestLoader(c.data, nil))
+
+			err := cfg.Load()
+			if c.isErr {
+				if err == nil {
+					t.Fatal("No error when error expected")
+				} else if c.notFound != errors.Is(err, ErrNotFound) {
+					t.Errorf("Not found %v != %v", c.notFound, errors.Is(err, ErrNotFound))
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			if actual := cfg.MustGetString(c.key); actual != c.expected {
+				t.Errorf("%s != %s", c.expected, actual)
+			}
+			if p, _ := cfg.Provenance(c.key); p.Sensitive != c.sensitive {
+				t.Errorf("Sensitive %v != %v", c.sensitive, p.Sensitive)
+			}
+		})
+	}
+}
+
+func Test_Config_TrustLoaders(t *testing.T) {
+	t.Setenv("CFG_TEST_DB_PASS", "from-env")
+
+	cfg := New()
+	cfg.RegisterResolver("env", EnvResolver)
+	cfg.TrustLoaders("app")
+	cfg.AddNamed("app", newTestLoader(map[string]any{"password": "env://CFG_TEST_DB_PASS"}, nil), PriorityNormal)
+	cfg.AddNamed("remote", newTestLoader(map[string]any{"token": "env://CFG_TEST_DB_PASS"}, nil), PriorityNormal)
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if actual := cfg.MustGetString("password"); actual != "from-env" {
+		t.Errorf("from-env != %s", actual)
+	}
+	if actual := cfg.MustGetString("token"); actual != "env://CFG_TEST_DB_PASS" {
+		t.Errorf("env://CFG_TEST_DB_PASS != %s", actual)
+	}
+}
@@ -0,0 +1,130 @@
+package cfg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Controls how a loader is retried when it fails. Zero values are replaced with the values from
+// DefaultRetryPolicy
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first
+	MaxAttempts int
+
+	// Delay before the first retry. Each following delay is multiplied by Multiplier, up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// Fraction of each delay that is randomized, between 0 and 1, so that many instances starting at
+	// once do not retry in lockstep
+	Jitter float64
+
+	// Determines whether an error should be retried. By default all errors are retried except
+	// missing sources. Retries always stop once the context passed to the loader is done, but an
+	// attempt that times out on its own, such as one wrapped with WithTimeout, is retried
+	Retryable func(error) bool
+}
+
+// Retry policy used for any values that are not set
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	Retryable:      isRetryable,
+}
+
+func isRetryable(err error) bool {
+	return !isNotFound(err)
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	if p.Jitter <= 0 || p.Jitter > 1 {
+		p.Jitter = DefaultRetryPolicy.Jitter
+	}
+	if p.Retryable == nil {
+		p.Retryable = DefaultRetryPolicy.Retryable
+	}
+
+	return p
+}
+
+// Gets the delay before the given retry, starting from 0
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 0; i < retry && d < float64(p.MaxBackoff); i++ {
+		d *= p.Multiplier
+	}
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+
+	// Randomize within [d * (1 - jitter), d]
+	d -= d * p.Jitter * rand.Float64()
+	return time.Duration(d)
+}
+
+type retryLoader struct {
+	loader Loader
+	policy RetryPolicy
+}
+
+// Loads configuration from a source into a map, retrying on failure
+func (l *retryLoader) Load() (map[string]any, error) {
+	return l.LoadContext(context.Background())
+}
+
+// Loads configuration from a source into a map, retrying on failure until the context is done
+func (l *retryLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	var err error
+	for attempt := 0; attempt < l.policy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(l.policy.backoff(attempt - 1))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			}
+		}
+
+		var data map[string]any
+		data, err = loadContext(ctx, l.loader)
+		if err == nil {
+			return data, nil
+		} else if ctx.Err() != nil || !l.policy.Retryable(err) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("failed after %d attempts: %w", l.policy.MaxAttempts, err)
+}
+
+// Gets the wrapped loader
+func (l *retryLoader) Unwrap() Loader {
+	return l.loader
+}
+
+// Wraps a loader so that failed loads are retried with exponential backoff and jitter, according to
+// the given policy
+func Retry(l Loader, policy RetryPolicy) Loader {
+	return &retryLoader{loader: l, policy: policy.withDefaults()}
+}
+
+var _ ContextLoader = (*retryLoader)(nil)
@@ -0,0 +1,146 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+type testFlakyLoader struct {
+	failures int
+	err      error
+	attempts int
+
+	// Failed attempts wait for the context to be done instead of returning err
+	hang bool
+}
+
+func (loader *testFlakyLoader) Load() (map[string]any, error) {
+	return loader.LoadContext(context.Background())
+}
+
+func (loader *testFlakyLoader) LoadContext(ctx context.Context) (map[string]any, error) {
+	loader.attempts++
+	if loader.attempts <= loader.failures && loader.hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	} else if loader.attempts <= loader.failures {
+		return nil, loader.err
+	}
+
+	return testData, nil
+}
+
+func Test_Retry(t *testing.T) {
+	errPermanent := errors.New("permanent")
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+		Retryable: func(err error) bool {
+			return !errors.Is(err, errPermanent)
+		},
+	}
+
+	cases := []struct {
+		name     string
+		loader   *testFlakyLoader
+		policy   RetryPolicy
+		timeout  time.Duration
+		attempts int
+		err      error
+	}{
+		{
+			name:     "Succeeds after retries",
+			loader:   &testFlakyLoader{failures: 2, err: errTest},
+			policy:   policy,
+			attempts: 3,
+		},
+		{
+			name:     "Exhausts attempts",
+			loader:   &testFlakyLoader{failures: 5, err: errTest},
+			policy:   policy,
+			attempts: 3,
+			err:      errTest,
+		},
+		{
+			name:     "Not retryable",
+			loader:   &testFlakyLoader{failures: 5, err: errPermanent},
+			policy:   policy,
+			attempts: 1,
+			err:      errPermanent,
+		},
+		{
+			name:     "Default does not retry not found",
+			loader:   &testFlakyLoader{failures: 5, err: os.ErrNotExist},
+			policy:   RetryPolicy{InitialBackoff: time.Millisecond},
+			attempts: 1,
+			err:      os.ErrNotExist,
+		},
+		{
+			name:     "Default retries attempt timeouts",
+			loader:   &testFlakyLoader{failures: 2, hang: true},
+			policy:   RetryPolicy{InitialBackoff: time.Millisecond},
+			timeout:  5 * time.Millisecond,
+			attempts: 3,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var l Loader = c.loader
+			if c.timeout > 0 {
+				l = WithTimeout(l, c.timeout)
+			}
+
+			_, err := Retry(l, c.policy).Load()
+			if c.err == nil && err != nil {
+				t.Errorf("Unexpected error %v", err)
+			} else if !errors.Is(err, c.err) {
+				t.Errorf("%v != %v", c.err, err)
+			}
+			if c.attempts != c.loader.attempts {
+				t.Errorf("Attempts %d != %d", c.attempts, c.loader.attempts)
+			}
+		})
+	}
+}
+
+func Test_Retry_Cancelled(t *testing.T) {
+	loader := Retry(&testFlakyLoader{failures: 5, err: errTest}, RetryPolicy{InitialBackoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := loader.(ContextLoader).LoadContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("%v != %v", context.DeadlineExceeded, err)
+	}
+}
+
+func Test_RetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+	}.withDefaults()
+
+	cases := []struct {
+		retry int
+		max   time.Duration
+	}{
+		{retry: 0, max: 100 * time.Millisecond},
+		{retry: 1, max: 200 * time.Millisecond},
+		{retry: 2, max: 400 * time.Millisecond},
+		{retry: 10, max: time.Second},
+	}
+
+	for _, c := range cases {
+		actual := policy.backoff(c.retry)
+		if actual > c.max || actual < c.max/2 {
+			t.Errorf("Backoff %d: %v not in [%v, %v]", c.retry, actual, c.max/2, c.max)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package cfg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Identifies the expected type of a configuration value
+type Type int
+
+const (
+	// Accepts a value of any type
+	TypeAny Type = iota
+	TypeString
+	TypeInt
+	TypeFloat64
+	TypeBool
+
+	// Validated like a string, but the value is treated as sensitive and never displayed
+	TypeSecret
+
+	// A string parsed with time.ParseDuration e.g. "1m30s"
+	TypeDuration
+)
+
+// Gets a human readable name of the type
+func (t Type) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeInt:
+		return "int"
+	case TypeFloat64:
+		return "float64"
+	case TypeBool:
+		return "bool"
+	case TypeSecret:
+		return "secret"
+	case TypeDuration:
+		return "duration"
+	default:
+		return "any"
+	}
+}
+
+// Describes a single configuration key expected by the application. Declared fields are validated
+// every time configuration is loaded
+type Field struct {
+	// Key path of the value, using the same format as the getters e.g. "db:port"
+	Key string
+
+	// Expected type of the value. The value must be convertible to this type by its getter
+	Type Type
+
+	// If set, loading fails when no source provides the value and there is no default
+	Required bool
+
+	// Value used when no source provides the key. Defaults are validated like any other value
+	Default any
+
+	// If set, the value must be equal to one of these values after conversion to Type
+	Allowed []any
+
+	// Inclusive bounds for numeric values, or for the length of string values. Use Bound to create
+	// these inline
+	Min *float64
+	Max *float64
+
+	// If set, string values must match this regular expression
+	Pattern string
+
+	// Human readable explanation of the value
+	Description string
+}
+
+// Creates a numeric bound for use as a field minimum or maximum
+func Bound(v float64) *float64 {
+	return &v
+}
+
+// Describes a single way in which loaded configuration does not satisfy the declared schema
+type Violation struct {
+	// Key path of the value that failed validation
+	Key string
+
+	// Path of the struct field the value was bound to e.g. "Server.Port". Empty if the value was
+	// not bound into a struct
+	Field string
+
+	// Explanation of the failure
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Field != "" {
+		return fmt.Sprintf("%s (%s) %s", v.Key, v.Field, v.Message)
+	}
+
+	return fmt.Sprintf("%s %s", v.Key, v.Message)
+}
+
+// Returned when loaded configuration does not satisfy the declared schema. Contains every
+// violation that was found rather than only the first
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (err *ValidationError) Error() string {
+	msgs := make([]string, len(err.Violations))
+	for i, v := range err.Violations {
+		msgs[i] = v.String()
+	}
+
+	return fmt.Sprintf("invalid configuration: %s", strings.Join(msgs, "; "))
+}
+
+// Declares fields that are expected in this configuration. Fields are validated and defaults are
+// applied the next time configuration is loaded. Declaring a key that was already declared
+// replaces the previous declaration
+func (cfg *Config) Declare(fields ...Field) {
+	for _, f := range fields {
+		replaced := false
+		for i := range cfg.fields {
+			if cfg.fields[i].Key == f.Key {
+				cfg.fields[i] = f
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			cfg.fields = append(cfg.fields, f)
+		}
+	}
+}
+
+func toBool(key string, v any) (bool, error) {
+	if vBool, ok := v.(bool); ok {
+		return vBool, nil
+	} else if vStr, ok := v.(string); ok {
+		return strconv.ParseBool(vStr)
+	} else {
+		return false, fmt.Errorf("key %s does not have a valid bool value", key)
+	}
+}
+
+func toDuration(key string, v any) (time.Duration, error) {
+	if vDur, ok := v.(time.Duration); ok {
+		return vDur, nil
+	} else if vStr, ok := v.(string); ok {
+		return time.ParseDuration(vStr)
+	} else {
+		return 0, fmt.Errorf("key %s does not have a valid duration value", key)
+	}
+}
+
+func convertType(t Type, key string, v any) (any, error) {
+	switch t {
+	case TypeString, TypeSecret:
+		return toString(key, v)
+	case TypeInt:
+		return toInt(key, v)
+	case TypeFloat64:
+		return toFloat64(key, v)
+	case TypeBool:
+		return toBool(key, v)
+	case TypeDuration:
+		return toDuration(key, v)
+	default:
+		return v, nil
+	}
+}
+
+func (f Field) validate(v any) []string {
+	converted, err := convertType(f.Type, f.Key, v)
+	if err != nil {
+		return []string{fmt.Sprintf("must be a valid %s value", f.Type)}
+	}
+
+	msgs := make([]string, 0)
+	if len(f.Allowed) > 0 {
+		found := false
+		names := make([]string, len(f.Allowed))
+		for i, a := range f.Allowed {
+			names[i] = fmt.Sprint(a)
+			if ac, err := convertType(f.Type, f.Key, a); err == nil && fmt.Sprint(ac) == fmt.Sprint(converted) {
+				found = true
+			}
+		}
+
+		if !found {
+			msgs = append(msgs, fmt.Sprintf("must be one of %s", strings.Join(names, ", ")))
+		}
+	}
+
+	if f.Type == TypeInt || f.Type == TypeFloat64 {
+		num, _ := toFloat64(f.Key, converted)
+		if f.Min != nil && num < *f.Min {
+			msgs = append(msgs, fmt.Sprintf("must be at least %v", *f.Min))
+		}
+		if f.Max != nil && num > *f.Max {
+			msgs = append(msgs, fmt.Sprintf("must be at most %v", *f.Max))
+		}
+	} else if str, ok := converted.(string); ok {
+		if f.Min != nil && float64(len(str)) < *f.Min {
+			msgs = append(msgs, fmt.Sprintf("must be at least %v characters", *f.Min))
+		}
+		if f.Max != nil && float64(len(str)) > *f.Max {
+			msgs = append(msgs, fmt.Sprintf("must be at most %v characters", *f.Max))
+		}
+	}
+
+	if str, ok := converted.(string); ok && f.Pattern != "" {
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("has an invalid pattern: %v", err))
+		} else if !re.MatchString(str) {
+			msgs = append(msgs, fmt.Sprintf("must match pattern %s", f.Pattern))
+		}
+	}
+
+	return msgs
+}
+
+// Applies defaults for missing declared keys and validates all declared keys. Returns every
+// violation that was found
+func (cfg *Config) validate(data map[string]any) error {
+	violations := make([]Violation, 0)
+
+	for _, f := range cfg.fields {
+		key := cfg.normalizeKey(f.Key)
+		v := data[key]
+		if v == nil {
+			if f.Default != nil {
+				v = f.Default
+				data[key] = v
+			} else {
+				if f.Required {
+					violations = append(violations, Violation{Key: f.Key, Message: "is required"})
+				}
+				continue
+			}
+		}
+
+		for _, msg := range f.validate(v) {
+			violations = append(violations, Violation{Key: f.Key, Message: msg})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	return nil
+}
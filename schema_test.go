@@ -0,0 +1,131 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Config_Declare(t *testing.T) {
+	cases := []struct {
+		name       string
+		data       map[string]any
+		fields     []Field
+		violations []string
+	}{
+		{
+			name: "Valid",
+			data: map[string]any{"port": 8080, "level": "info", "name": "svc"},
+			fields: []Field{
+				{Key: "port", Type: TypeInt, Required: true, Min: Bound(1), Max: Bound(65535)},
+				{Key: "level", Type: TypeString, Allowed: []any{"debug", "info"}},
+				{Key: "name", Type: TypeString, Pattern: "^[a-z]+$"},
+			},
+			violations: []string{},
+		},
+		{
+			name:       "Missing required",
+			data:       map[string]any{},
+			fields:     []Field{{Key: "port", Type: TypeInt, Required: true}},
+			violations: []string{"port is required"},
+		},
+		{
+			name:       "Missing optional",
+			data:       map[string]any{},
+			fields:     []Field{{Key: "port", Type: TypeInt}},
+			violations: []string{},
+		},
+		{
+			name:       "Wrong type",
+			data:       map[string]any{"port": "abc"},
+			fields:     []Field{{Key: "port", Type: TypeInt}},
+			violations: []string{"port must be a valid int value"},
+		},
+		{
+			name:       "Invalid duration",
+			data:       map[string]any{"timeout": "30"},
+			fields:     []Field{{Key: "timeout", Type: TypeDuration}},
+			violations: []string{"timeout must be a valid duration value"},
+		},
+		{
+			name:       "Out of range",
+			data:       map[string]any{"port": "70000"},
+			fields:     []Field{{Key: "port", Type: TypeInt, Min: Bound(1), Max: Bound(65535)}},
+			violations: []string{"port must be at most 65535"},
+		},
+		{
+			name:       "Not allowed",
+			data:       map[string]any{"level": "trace"},
+			fields:     []Field{{Key: "level", Type: TypeString, Allowed: []any{"debug", "info"}}},
+			violations: []string{"level must be one of debug, info"},
+		},
+		{
+			name:       "Pattern mismatch",
+			data:       map[string]any{"name": "SVC"},
+			fields:     []Field{{Key: "name", Type: TypeString, Pattern: "^[a-z]+$"}},
+			violations: []string{"name must match pattern ^[a-z]+$"},
+		},
+		{
+			name:       "Invalid default",
+			data:       map[string]any{},
+			fields:     []Field{{Key: "port", Type: TypeInt, Default: 0, Min: Bound(1)}},
+			violations: []string{"port must be at least 1"},
+		},
+		{
+			name: "All violations",
+			data: map[string]any{"port": 0, "level": "trace"},
+			fields: []Field{
+				{Key: "port", Type: TypeInt, Min: Bound(1)},
+				{Key: "level", Type: TypeString, Allowed: []any{"debug", "info"}},
+				{Key: "name", Type: TypeString, Required: true},
+			},
+			violations: []string{"port must be at least 1", "level must be one of debug, info", "name is required"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := New()
+			cfg.Add(newTestLoader(c.data, nil))
+			cfg.Declare(c.fields...)
+
+			err := cfg.Load()
+			var vErr *ValidationError
+			if len(c.violations) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error %v", err)
+				}
+				return
+			} else if !errors.As(err, &vErr) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+
+			if len(c.violations) != len(vErr.Violations) {
+				t.Fatalf("Violations %v != %v", c.violations, vErr.Violations)
+			}
+			for i, v := range vErr.Violations {
+				if c.violations[i] != v.String() {
+					t.Errorf("Violation %s != %s", c.violations[i], v.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_Config_Declare_Default(t *testing.T) {
+	cfg := New()
+	cfg.Add(newTestLoader(map[string]any{"host": "localhost"}, nil))
+	cfg.Declare(
+		Field{Key: "host", Type: TypeString, Default: "0.0.0.0"},
+		Field{Key: "port", Type: TypeInt, Default: 8080},
+	)
+
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if actual := cfg.MustGetString("host"); actual != "localhost" {
+		t.Errorf("Host localhost != %s", actual)
+	}
+	if actual := cfg.MustGetInt("port"); actual != 8080 {
+		t.Errorf("Port 8080 != %d", actual)
+	}
+}
@@ -0,0 +1,59 @@
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+const redacted = "[REDACTED]"
+
+// Holds a sensitive configuration value such as a password or API token. The contents are
+// redacted whenever the value is printed, marshalled or logged and can only be accessed
+// through Reveal
+type Secret struct {
+	// Stored behind a pointer so that printing a struct with an unexported Secret field only
+	// ever shows an address rather than the contents
+	value *string
+}
+
+// Creates a new secret holding the given value
+func NewSecret(value string) Secret {
+	return Secret{value: &value}
+}
+
+// Returns the underlying secret value
+func (s Secret) Reveal() string {
+	if s.value == nil {
+		return ""
+	}
+
+	return *s.value
+}
+
+// Returns a redacted placeholder, never the secret value
+func (s Secret) String() string {
+	return redacted
+}
+
+// Returns a redacted placeholder for the %#v verb, never the secret value
+func (s Secret) GoString() string {
+	return "cfg.Secret(" + redacted + ")"
+}
+
+// Marshals the secret as a redacted placeholder string
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// Logs the secret as a redacted placeholder
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+var (
+	_ fmt.Stringer   = Secret{}
+	_ fmt.GoStringer = Secret{}
+	_ json.Marshaler = Secret{}
+	_ slog.LogValuer = Secret{}
+)
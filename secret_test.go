@@ -0,0 +1,115 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func Test_Secret_Redaction(t *testing.T) {
+	secret := NewSecret("hunter2")
+	settings := struct {
+		Password Secret
+		token    Secret
+	}{
+		Password: secret,
+		token:    secret,
+	}
+
+	var logBuf bytes.Buffer
+	slog.New(slog.NewTextHandler(&logBuf, nil)).Info("settings", "password", secret)
+	jsonBytes, err := json.Marshal(settings)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	cases := []struct {
+		name   string
+		output string
+	}{
+		{name: "String", output: secret.String()},
+		{name: "%v", output: fmt.Sprintf("%v", secret)},
+		{name: "%s", output: fmt.Sprintf("%s", secret)},
+		{name: "%#v", output: fmt.Sprintf("%#v", secret)},
+		{name: "%+v struct", output: fmt.Sprintf("%+v", settings)},
+		{name: "%#v struct", output: fmt.Sprintf("%#v", settings)},
+		{name: "JSON", output: string(jsonBytes)},
+		{name: "slog", output: logBuf.String()},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if strings.Contains(c.output, "hunter2") {
+				t.Errorf("Secret leaked in %s", c.output)
+			}
+		})
+	}
+}
+
+func Test_Secret_Reveal(t *testing.T) {
+	if actual := NewSecret("hunter2").Reveal(); actual != "hunter2" {
+		t.Errorf("Value hunter2 != %s", actual)
+	}
+	if actual := (Secret{}).Reveal(); actual != "" {
+		t.Errorf("Zero value revealed %s", actual)
+	}
+}
+
+func Test_Config_GetSecret(t *testing.T) {
+	cfg, err := newConfigAndLoad(newTestLoader(map[string]any{"password": "hunter2", "port": 5}, nil))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	secret, err := cfg.GetSecret("password")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if secret.Reveal() != "hunter2" {
+		t.Errorf("Value hunter2 != %s", secret.Reveal())
+	}
+	if cfg.MustGetSecret("password").Reveal() != "hunter2" {
+		t.Error("Must value does not match")
+	}
+	if _, err := cfg.GetSecret("port"); err == nil {
+		t.Error("No error when error expected")
+	}
+	if _, err := cfg.GetSecret("missing"); err == nil {
+		t.Error("No error when error expected")
+	}
+}
+
+func Test_Binder_SecretVar(t *testing.T) {
+	cfg, err := newConfigAndLoad(newTestLoader(map[string]any{"password": "hunter2", "name": "svc"}, nil))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var name string
+	var password Secret
+	err = cfg.Bind(func(b *Binder) {
+		b.StringVar(&name, "name")
+		b.SecretVar(&password, "password")
+	})
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if password.Reveal() != "hunter2" {
+		t.Errorf("Secret hunter2 != %s", password.Reveal())
+	}
+	if name != "svc" {
+		t.Errorf("String svc != %s", name)
+	}
+
+	var missing Secret
+	err = cfg.Bind(func(b *Binder) {
+		b.SecretVar(&missing, "missing")
+	})
+	if err == nil {
+		t.Error("No error when error expected")
+	}
+}
@@ -0,0 +1,215 @@
+package cfg
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	secretType   = reflect.TypeOf(Secret{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// A bindable field found while walking a struct
+type structField struct {
+	// Go path of the field e.g. "Server.Port"
+	path string
+
+	// Segments of the config key of the field e.g. ["server", "port"]
+	segs []string
+
+	index       []int
+	typ         Type
+	description string
+
+	// Rules parsed from the "validate" tag
+	rules Field
+}
+
+// Parses a validation tag such as "required,min=1,max=65535" into the rules of a field. Supports
+// required, min, max and oneof, where oneof takes a space separated list of allowed values
+func parseValidateTag(tag string) (Field, error) {
+	var rules Field
+	if tag == "" {
+		return rules, nil
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			rules.Required = true
+		case "min", "max":
+			bound, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				return rules, fmt.Errorf("invalid %s rule %q", name, arg)
+			}
+
+			if name == "min" {
+				rules.Min = &bound
+			} else {
+				rules.Max = &bound
+			}
+		case "oneof":
+			for _, allowed := range strings.Fields(arg) {
+				rules.Allowed = append(rules.Allowed, allowed)
+			}
+		default:
+			return rules, fmt.Errorf("unknown validation rule %q", name)
+		}
+	}
+
+	return rules, nil
+}
+
+// Walks all bindable fields of a struct type. Fields use the key given in their "cfg" tag, or their
+// lowercased name if no tag is given. Nested structs are prefixed with the key of the parent field,
+// and fields tagged with "-" are skipped. Returns an error if any field has an invalid "validate" tag
+func walkStruct(t reflect.Type) ([]structField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t)
+	}
+
+	fields := make([]structField, 0)
+	if err := walkStructPrefixed(t, nil, "", nil, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+func walkStructPrefixed(t reflect.Type, index []int, path string, segs []string, fields *[]structField) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("cfg")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+
+		sf := structField{
+			path:        f.Name,
+			segs:        append(append([]string{}, segs...), name),
+			index:       append(append([]int{}, index...), i),
+			description: f.Tag.Get("desc"),
+		}
+		if path != "" {
+			sf.path = fmt.Sprintf("%s.%s", path, f.Name)
+		}
+
+		rules, err := parseValidateTag(f.Tag.Get("validate"))
+		if err != nil {
+			return fmt.Errorf("field %s: %w", sf.path, err)
+		}
+
+		switch {
+		case f.Type == secretType:
+			sf.typ = TypeSecret
+		case f.Type == durationType:
+			sf.typ = TypeDuration
+		case f.Type.Kind() == reflect.String:
+			sf.typ = TypeString
+		case f.Type.Kind() == reflect.Int, f.Type.Kind() == reflect.Int64:
+			sf.typ = TypeInt
+		case f.Type.Kind() == reflect.Float64:
+			sf.typ = TypeFloat64
+		case f.Type.Kind() == reflect.Bool:
+			sf.typ = TypeBool
+		case f.Type.Kind() == reflect.Struct:
+			if err := walkStructPrefixed(f.Type, sf.index, sf.path, sf.segs, fields); err != nil {
+				return err
+			}
+			continue
+		default:
+			return fmt.Errorf("field %s has unsupported type %s", sf.path, f.Type)
+		}
+
+		// Bounds apply to numbers and the lengths of strings, so they are rejected for other types
+		// rather than silently ignored
+		if (rules.Min != nil || rules.Max != nil) && (sf.typ == TypeBool || sf.typ == TypeDuration) {
+			return fmt.Errorf("field %s: min and max rules are not supported for %s values", sf.path, sf.typ)
+		}
+
+		rules.Type = sf.typ
+		sf.rules = rules
+		*fields = append(*fields, sf)
+	}
+
+	return nil
+}
+
+// Binds configuration values into the fields of the struct pointed to by dest. Each field is bound
+// to the key in its "cfg" tag, or its lowercased name if no tag is given, and nested structs are
+// bound under the key of their parent field. Fields of type string, int, int64, float64, bool,
+// time.Duration, Secret or nested structs are supported. Fields whose keys are not found are left unmodified.
+//
+// Fields may declare rules in a "validate" tag e.g. `validate:"required,min=1,max=65535"`. Supported
+// rules are required, min, max (bounds for numbers, lengths for strings) and oneof (a space separated
+// list of allowed values). If any value cannot be converted or breaks a rule, a *ValidationError
+// listing every violation by key and field path is returned and none of the fields are modified
+func (cfg *Config) BindStruct(dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("bind destination must be a non-nil pointer to a struct")
+	}
+
+	fields, err := walkStruct(v.Elem().Type())
+	if err != nil {
+		return err
+	}
+
+	// Values are all read from a single load, even if configuration is reloaded while binding
+	s := cfg.snapshot()
+	violations := make([]Violation, 0)
+	values := make([]any, len(fields))
+	for i, f := range fields {
+		key := cfg.Key(f.segs...)
+		f.rules.Key = key
+		cfg.usage.add(Field{Key: key, Type: f.typ, Required: f.rules.Required, Description: f.description})
+
+		raw := s.lookup(key)
+		if raw == nil {
+			if f.rules.Required {
+				violations = append(violations, Violation{Key: key, Field: f.path, Message: "is required"})
+			}
+			continue
+		}
+
+		msgs := f.rules.validate(raw)
+		for _, msg := range msgs {
+			violations = append(violations, Violation{Key: key, Field: f.path, Message: msg})
+		}
+		if len(msgs) > 0 {
+			continue
+		}
+
+		if f.typ == TypeSecret {
+			values[i], err = s.getSecret(key)
+		} else {
+			values[i], err = convertType(f.typ, key, raw)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	for i, f := range fields {
+		if values[i] != nil {
+			target := v.Elem().FieldByIndex(f.index)
+			target.Set(reflect.ValueOf(values[i]).Convert(target.Type()))
+		}
+	}
+
+	return nil
+}
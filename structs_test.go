@@ -0,0 +1,236 @@
+package cfg
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testLevel string
+
+type testServer struct {
+	Host string
+	Port int `cfg:"port"`
+}
+
+type testSettings struct {
+	Name     string        `cfg:"name"`
+	Level    testLevel     `cfg:"level"`
+	Ratio    float64       `cfg:"ratio"`
+	Password Secret        `cfg:"password"`
+	Server   testServer    `cfg:"server"`
+	Debug    bool          `cfg:"debug"`
+	MaxSize  int64         `cfg:"maxsize"`
+	Timeout  time.Duration `cfg:"timeout"`
+	Ignored  string        `cfg:"-"`
+	internal string
+}
+
+func Test_Config_BindStruct(t *testing.T) {
+	cfg, err := newConfigAndLoad(newTestLoader(map[string]any{
+		"name":     "svc",
+		"level":    "info",
+		"ratio":    "0.5",
+		"password": "hunter2",
+		"ignored":  "value",
+		"debug":    "true",
+		"maxsize":  float64(1 << 20),
+		"timeout":  "1m30s",
+		"server": map[string]any{
+			"host": "localhost",
+			"port": float64(8080),
+		},
+	}, nil))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var actual testSettings
+	if err := cfg.BindStruct(&actual); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if actual.Name != "svc" {
+		t.Errorf("Name svc != %s", actual.Name)
+	}
+	if actual.Level != "info" {
+		t.Errorf("Level info != %s", actual.Level)
+	}
+	if actual.Ratio != 0.5 {
+		t.Errorf("Ratio 0.5 != %f", actual.Ratio)
+	}
+	if actual.Password.Reveal() != "hunter2" {
+		t.Errorf("Password hunter2 != %s", actual.Password.Reveal())
+	}
+	if actual.Server.Host != "localhost" {
+		t.Errorf("Host localhost != %s", actual.Server.Host)
+	}
+	if actual.Server.Port != 8080 {
+		t.Errorf("Port 8080 != %d", actual.Server.Port)
+	}
+	if !actual.Debug {
+		t.Error("Debug was not bound")
+	}
+	if actual.MaxSize != 1<<20 {
+		t.Errorf("MaxSize %d != %d", 1<<20, actual.MaxSize)
+	}
+	if actual.Timeout != 90*time.Second {
+		t.Errorf("Timeout %v != %v", 90*time.Second, actual.Timeout)
+	}
+	if actual.Ignored != "" {
+		t.Errorf("Ignored field was bound to %s", actual.Ignored)
+	}
+}
+
+func Test_Config_BindStruct_Errors(t *testing.T) {
+	cases := []struct {
+		name string
+		dest any
+	}{
+		{name: "Not a pointer", dest: testSettings{}},
+		{name: "Not a struct", dest: new(string)},
+		{name: "Unsupported field", dest: &struct{ Values []string }{}},
+		{name: "Invalid value", dest: &struct{ Port int }{}},
+		{name: "Invalid duration", dest: &struct{ Port time.Duration }{}},
+	}
+
+	cfg, err := newConfigAndLoad(newTestLoader(map[string]any{"port": "abc"}, nil))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := cfg.BindStruct(c.dest); err == nil {
+				t.Error("No error when error expected")
+			}
+		})
+	}
+}
+
+func Test_Config_BindStruct_Atomic(t *testing.T) {
+	cfg, err := newConfigAndLoad(newTestLoader(map[string]any{"name": "svc", "port": "abc"}, nil))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var actual struct {
+		Name string
+		Port int
+	}
+	if err := cfg.BindStruct(&actual); err == nil {
+		t.Error("No error when error expected")
+	}
+	if actual.Name != "" {
+		t.Errorf("Name was modified to %s", actual.Name)
+	}
+}
+
+func Test_Config_BindStruct_Validate(t *testing.T) {
+	type settings struct {
+		Level  string `cfg:"level" validate:"oneof=debug info warn"`
+		Name   string `cfg:"name" validate:"required,min=3"`
+		Server struct {
+			Port int `validate:"required,min=1,max=65535"`
+		} `cfg:"server"`
+	}
+
+	cases := []struct {
+		name       string
+		data       map[string]any
+		violations []string
+	}{
+		{
+			name: "Valid",
+			data: map[string]any{"level": "info", "name": "svc", "server": map[string]any{"port": 80}},
+		},
+		{
+			name: "Missing required",
+			data: map[string]any{"level": "info"},
+			violations: []string{
+				"name (Name) is required",
+				"server:port (Server.Port) is required",
+			},
+		},
+		{
+			name: "Invalid values",
+			data: map[string]any{"level": "trace", "name": "ab", "server": map[string]any{"port": "abc"}},
+			violations: []string{
+				"level (Level) must be one of debug, info, warn",
+				"name (Name) must be at least 3 characters",
+				"server:port (Server.Port) must be a valid int value",
+			},
+		},
+		{
+			name:       "Out of range",
+			data:       map[string]any{"name": "svc", "server": map[string]any{"port": 70000}},
+			violations: []string{"server:port (Server.Port) must be at most 65535"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg, err := newConfigAndLoad(newTestLoader(c.data, nil))
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			var actual settings
+			err = cfg.BindStruct(&actual)
+			var vErr *ValidationError
+			if len(c.violations) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error %v", err)
+				}
+				return
+			} else if !errors.As(err, &vErr) {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+
+			if len(c.violations) != len(vErr.Violations) {
+				t.Fatalf("Violations %v != %v", c.violations, vErr.Violations)
+			}
+			for i, v := range vErr.Violations {
+				if c.violations[i] != v.String() {
+					t.Errorf("Violation %s != %s", c.violations[i], v.String())
+				}
+			}
+			if actual.Level != "" || actual.Name != "" {
+				t.Error("Fields modified despite violations")
+			}
+		})
+	}
+}
+
+func Test_Config_BindStruct_InvalidTag(t *testing.T) {
+	cfg, err := newConfigAndLoad(newTestLoader(map[string]any{}, nil))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	cases := []struct {
+		name string
+		dest any
+	}{
+		{name: "Unknown rule", dest: &struct {
+			Port int `validate:"positive"`
+		}{}},
+		{name: "Invalid bound", dest: &struct {
+			Port int `validate:"min=one"`
+		}{}},
+		{name: "Bound on bool", dest: &struct {
+			Enabled bool `validate:"min=1"`
+		}{}},
+		{name: "Bound on duration", dest: &struct {
+			Timeout time.Duration `validate:"max=30"`
+		}{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := cfg.BindStruct(c.dest); err == nil {
+				t.Error("No error when error expected")
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package cfg
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// Implemented by loaders that read values from environment variables, so that usage output can show
+// the variable that sets each key
+type EnvNamer interface {
+	// Gets the name of the environment variable that sets the value at the given key path
+	EnvName(path []string) string
+}
+
+// Records keys that are bound by the application so they can be listed in usage output
+type usageRegistry struct {
+	mu     sync.Mutex
+	fields map[string]Field
+}
+
+func newUsageRegistry() *usageRegistry {
+	return &usageRegistry{
+		fields: make(map[string]Field),
+	}
+}
+
+func (r *usageRegistry) add(f Field) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fields[f.Key] = f
+}
+
+func (r *usageRegistry) list() []Field {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fields := make([]Field, 0, len(r.fields))
+	for _, f := range r.fields {
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// Gets the conventional command line flag name for a key path e.g. "db:port" becomes "-db-port"
+func flagName(path []string) string {
+	return fmt.Sprintf("-%s", strings.Join(path, "-"))
+}
+
+// Gets all keys that have been declared or bound, with declarations taking precedence, sorted by key
+func (cfg *Config) usageFields() []Field {
+	merged := make(map[string]Field)
+	for _, f := range cfg.usage.list() {
+		merged[cfg.normalizeKey(f.Key)] = f
+	}
+
+	for _, f := range cfg.fields {
+		key := cfg.normalizeKey(f.Key)
+		if bound, ok := merged[key]; ok {
+			if f.Type == TypeAny {
+				f.Type = bound.Type
+			}
+			if f.Description == "" {
+				f.Description = bound.Description
+			}
+			f.Required = f.Required || bound.Required
+		}
+		merged[key] = f
+	}
+
+	fields := make([]Field, 0, len(merged))
+	for _, f := range merged {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Key < fields[j].Key
+	})
+
+	return fields
+}
+
+// Prints a table of every key that has been declared or bound by the application, similar to
+// flag.PrintDefaults. Each key is listed with the environment variable that sets it (if an
+// environment loader has been added, even if it is wrapped), its conventional flag name, type,
+// default and description. Defaults of secret values are redacted
+func (cfg *Config) PrintUsage(w io.Writer) error {
+	var envNamer EnvNamer
+	for _, entry := range cfg.loaders {
+		if n, ok := unwrapAs[EnvNamer](entry.loader); ok {
+			envNamer = n
+			break
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tENV\tFLAG\tTYPE\tDEFAULT\tDESCRIPTION")
+
+	for _, f := range cfg.usageFields() {
+		path := cfg.splitKey(f.Key)
+		env := ""
+		if envNamer != nil {
+			env = envNamer.EnvName(path)
+		}
+
+		def := ""
+		if f.Type == TypeSecret && f.Default != nil {
+			def = redacted
+		} else if f.Default != nil {
+			def = fmt.Sprint(f.Default)
+		}
+
+		desc := f.Description
+		if f.Required {
+			desc = strings.TrimSpace(fmt.Sprintf("%s (required)", desc))
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", f.Key, env, flagName(path), f.Type, def, desc)
+	}
+
+	return tw.Flush()
+}
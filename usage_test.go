@@ -0,0 +1,73 @@
+package cfg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type testEnvLoader struct {
+	testLoader
+}
+
+func (loader testEnvLoader) EnvName(path []string) string {
+	return "APP_" + strings.ToUpper(strings.Join(path, "__"))
+}
+
+func Test_Config_PrintUsage(t *testing.T) {
+	cfg := New()
+	cfg.Add(&testEnvLoader{testLoader{data: map[string]any{"port": 80, "name": "svc"}}})
+	cfg.Declare(
+		Field{Key: "port", Type: TypeInt, Default: 8080, Description: "Port to listen on"},
+		Field{Key: "db:password", Type: TypeSecret, Default: "hunter2", Required: true},
+	)
+	if err := cfg.Load(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	var name string
+	var settings struct {
+		Level string `desc:"Log level"`
+	}
+	_ = cfg.Bind(func(b *Binder) {
+		b.StringVar(&name, "name")
+	})
+	_ = cfg.BindStruct(&settings)
+
+	var buf bytes.Buffer
+	if err := cfg.PrintUsage(&buf); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	expected := []string{
+		"KEY          ENV               FLAG          TYPE    DEFAULT     DESCRIPTION",
+		"db:password  APP_DB__PASSWORD  -db-password  secret  [REDACTED]  (required)",
+		"level        APP_LEVEL         -level        string              Log level",
+		"name         APP_NAME          -name         string              ",
+		"port         APP_PORT          -port         int     8080        Port to listen on",
+	}
+	actual := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(expected) != len(actual) {
+		t.Fatalf("Usage %q != %q", expected, actual)
+	}
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Errorf("Line %q != %q", expected[i], actual[i])
+		}
+	}
+}
+
+func Test_Config_PrintUsage_WrappedEnvLoader(t *testing.T) {
+	cfg := New()
+	cfg.Add(Optional(&testEnvLoader{testLoader{data: map[string]any{}}}))
+	cfg.Declare(Field{Key: "port", Type: TypeInt})
+
+	var buf bytes.Buffer
+	if err := cfg.PrintUsage(&buf); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if !strings.Contains(buf.String(), "APP_PORT") {
+		t.Errorf("No environment variable in usage %q", buf.String())
+	}
+}
@@ -0,0 +1,125 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Loader whose source can report when it changes, such as a key-value store supporting blocking
+// queries. Loaders wrapped by WithPolicy, WithTimeout or Retry are still watched
+type Watcher interface {
+	// Blocks until the source may have changed since it was last loaded or watched. Returns the
+	// context's error if it is done first
+	Watch(ctx context.Context) error
+}
+
+// Watches every registered loader that implements Watcher and reloads the configuration with
+// LoadContext whenever one of them reports a change. The result of each reload is passed to
+// onReload, if given, along with any errors from watching, which are retried with backoff. Changes
+// reported while a reload is running are picked up by that reload, and reloads that fail are
+// retried with backoff when the next change is reported. Blocks until the context
+// is done and returns its error, or returns an error immediately if no loaders can be watched
+func (cfg *Config) Watch(ctx context.Context, onReload func(error)) error {
+	if onReload == nil {
+		onReload = func(error) {}
+	}
+
+	changes := make(chan struct{}, 1)
+	errs := make(chan error)
+	watching := 0
+
+	for _, entry := range cfg.loaders {
+		w, ok := unwrapAs[Watcher](entry.loader)
+		if !ok {
+			continue
+		}
+
+		watching++
+		go watchLoader(ctx, entry.name, w, changes, errs)
+	}
+
+	if watching == 0 {
+		return errors.New("no registered loaders can be watched")
+	}
+
+	policy := DefaultRetryPolicy
+	failures := 0
+
+	for {
+		select {
+		case <-changes:
+		case err := <-errs:
+			onReload(err)
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		err := cfg.LoadContext(ctx)
+		onReload(err)
+
+		// Changes reported while reloading were picked up by the reload
+		select {
+		case <-changes:
+		default:
+		}
+
+		if err == nil {
+			failures = 0
+			continue
+		}
+
+		// Back off after a failed reload, as watchers may keep reporting the same change until
+		// their loader is reloaded successfully
+		timer := time.NewTimer(policy.backoff(failures))
+		failures++
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func watchLoader(ctx context.Context, name string, w Watcher, changes chan<- struct{}, errs chan<- error) {
+	policy := DefaultRetryPolicy
+	failures := 0
+
+	for {
+		err := w.Watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			if name != "" {
+				err = fmt.Errorf("watch %s: %w", name, err)
+			}
+
+			select {
+			case errs <- err:
+			case <-ctx.Done():
+				return
+			}
+
+			timer := time.NewTimer(policy.backoff(failures))
+			failures++
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+			continue
+		}
+
+		failures = 0
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package cfg
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testWatchLoader struct {
+	mu      sync.Mutex
+	data    map[string]any
+	changes chan struct{}
+}
+
+func (l *testWatchLoader) Load() (map[string]any, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.data, nil
+}
+
+func (l *testWatchLoader) Watch(ctx context.Context) error {
+	select {
+	case <-l.changes:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *testWatchLoader) set(data map[string]any) {
+	l.mu.Lock()
+	l.data = data
+	l.mu.Unlock()
+	l.changes <- struct{}{}
+}
+
+func Test_Config_Watch(t *testing.T) {
+	cases := []struct {
+		name string
+		wrap func(Loader) Loader
+	}{
+		{name: "Unwrapped", wrap: func(l Loader) Loader { return l }},
+		{name: "Optional", wrap: Optional},
+		{name: "Retry and timeout", wrap: func(l Loader) Loader { return Retry(WithTimeout(l, time.Second), RetryPolicy{}) }},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			loader := &testWatchLoader{data: map[string]any{"key": "old"}, changes: make(chan struct{})}
+			cfg := New()
+			cfg.Add(c.wrap(loader))
+			if err := cfg.Load(); err != nil {
+				t.Fatalf("%v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			reloads := make(chan error)
+			done := make(chan error)
+			go func() {
+				done <- cfg.Watch(ctx, func(err error) { reloads <- err })
+			}()
+
+			loader.set(map[string]any{"key": "new"})
+			if err := <-reloads; err != nil {
+				t.Fatalf("%v", err)
+			}
+			if actual := cfg.MustGetString("key"); actual != "new" {
+				t.Errorf("new != %s", actual)
+			}
+
+			cancel()
+			if err := <-done; err != context.Canceled {
+				t.Errorf("%v != %v", context.Canceled, err)
+			}
+		})
+	}
+}
+
+func Test_Config_Watch_NoWatchers(t *testing.T) {
+	cfg := New()
+	cfg.Add(newTestLoader(testData, nil))
+
+	if err := cfg.Watch(context.Background(), nil); err == nil {
+		t.Error("No error when error expected")
+	}
+}
+
+// Reports a change every time it is watched, like a watcher whose source stays changed until it is
+// reloaded
+type testChangedWatchLoader struct{}
+
+func (l testChangedWatchLoader) Load() (map[string]any, error) {
+	return testData, nil
+}
+
+func (l testChangedWatchLoader) Watch(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func Test_Config_Watch_FailedReloadBackoff(t *testing.T) {
+	cfg := New()
+	cfg.Add(testChangedWatchLoader{})
+	cfg.Add(newTestLoader(nil, errTest))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	reloads := 0
+	_ = cfg.Watch(ctx, func(err error) { reloads++ })
+
+	if reloads == 0 || reloads > 10 {
+		t.Errorf("Reloads %d not in [1, 10]", reloads)
+	}
+}